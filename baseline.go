@@ -0,0 +1,60 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// baselineKey identifies a Finding regardless of its Severity, so that
+// changing a rule's Severity does not invalidate a Baseline.
+type baselineKey struct {
+	Pathname string `json:"pathname"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// A Baseline is a snapshot of known findings. It lets a project adopt the
+// linter without first fixing every existing finding: findings in the Baseline
+// are suppressed, and only new ones are reported.
+type Baseline struct {
+	remaining map[baselineKey]int
+}
+
+// WriteBaseline writes findings to writer as a Baseline.
+func WriteBaseline(writer io.Writer, findings []Finding) error {
+	keys := []baselineKey{}
+	for _, f := range findings {
+		keys = append(keys, baselineKey{Pathname: f.Pathname, Rule: f.Rule, Message: f.Message})
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(keys)
+}
+
+// ReadBaseline reads a Baseline written by WriteBaseline.
+func ReadBaseline(reader io.Reader) (*Baseline, error) {
+	var keys []baselineKey
+	if e := json.NewDecoder(reader).Decode(&keys); e != nil {
+		return nil, e
+	}
+	b := &Baseline{remaining: map[baselineKey]int{}}
+	for _, k := range keys {
+		b.remaining[k] += 1
+	}
+	return b, nil
+}
+
+// Filter returns false if finding is in the Baseline, and true if it is new.
+// It is suitable for Report.Filter. Each finding in the Baseline suppresses
+// only 1 matching finding, so that new duplicates are still reported.
+func (b *Baseline) Filter(finding Finding) bool {
+	k := baselineKey{Pathname: finding.Pathname, Rule: finding.Rule, Message: finding.Message}
+	if b.remaining[k] > 0 {
+		b.remaining[k] -= 1
+		return false
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestBaseline(t *testing.T) {
+	lint := func(report *Report, text string) {
+		document, e := html.Parse(strings.NewReader(text))
+		if e != nil {
+			t.Fatal(e)
+		}
+		Lint(report, document, "goat.html")
+	}
+
+	old := `<p>"Hello"</p><a name="goat"></a>`
+	report := Report{Writer: &strings.Builder{}, Options: onlyRule("curly-quotes")}
+	report.Options.Enabled["a-name"] = true
+	lint(&report, old)
+	var buffer bytes.Buffer
+	if e := WriteBaseline(&buffer, report.Findings); e != nil {
+		t.Fatal(e)
+	}
+
+	baseline, e := ReadBaseline(&buffer)
+	if e != nil {
+		t.Fatal(e)
+	}
+	var builder strings.Builder
+	report = Report{Writer: &builder, Options: report.Options, Filter: baseline.Filter}
+	lint(&report, `<a name="goat"></a><p>"Hello"</p><p>"Goodbye"</p><a name="sheep"></a>`)
+
+	received := builder.String()
+	for _, e := range []string{`"Goodbye"`, "<a> has name; should use id"} {
+		if !strings.Contains(received, e) {
+			t.Errorf("received %q, expected %q", received, e)
+		}
+	}
+	if report.ErrorCount != 2 {
+		t.Errorf("received ErrorCount %d, expected 2: %q", report.ErrorCount, received)
+	}
+}
@@ -4,12 +4,21 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	lint "github.com/noncombatant/html_lint"
-	"golang.org/x/net/html"
 )
 
 const (
@@ -17,46 +26,440 @@ const (
 
 Usage:
 
-  html-lint [file [...]]
+  html-lint [options] [file-or-directory [...]]
 
-If no files are given, analyzes the standard input.`
+Analyzes the HTML files in any directories given, except those that match the
+gitignore-style patterns in the directory's .htmllintignore file. If no files
+are given, analyzes the standard input. Gzipped files, such as .html.gz, are
+decompressed first. With -fragment, analyzes partial documents, such as
+templates, skipping the rules that only make sense for complete documents.
+
+Options are read from .htmllint.json files in the directory of each HTML file
+and its ancestors, with nearer files taking precedence, and then from the
+command line. The JSON keys are the names of the options below that configure
+rules; "enabled", which maps rule names to true or false; and "severity", which
+maps rule names to error, warning, info, or off, overriding their defaults. The
+-fail-on threshold applies to the overridden severities.
+
+To adopt html-lint on an existing site, run it once with -write-baseline to
+record the current findings in the -baseline file. Later runs with -baseline
+report only findings that are not in it.
+
+To lint only what a change touches, give -diff a unified diff (such as the
+output of git diff, or - for the standard input). Only findings on lines that
+it adds are reported, not those on its context lines. If no files are given,
+analyzes the HTML files in the diff.
+
+With -serve, reads requests from the standard input, each a JSON object like
+{"path": "index.html", "text": "<!DOCTYPE html>..."} on its own line, and
+writes a JSON object like {"path": "index.html", "findings": [...]} for each,
+on its own line. A line that is not a valid request gets a response with an
+"error" instead. Findings include their line numbers, where known. Each request
+is linted with the options for its path, but -baseline and -diff do not apply.
+
+With -watch, lints the files given, and then lints each again whenever it
+changes, printing a line starting with --- before its new findings. The
+-baseline and -diff options apply only to the first lint.
+
+With -list-rules, lists the rules that html-lint knows, and exits. Opt-in rules
+run only when enabled; document rules do not run with -fragment.
+
+Exits with status 0 if there were no findings, 1 if there were lint findings
+at least as severe as -fail-on, and 2 if any file could not be read or parsed.
+
+Options:`
 )
 
+func exitStatus(report *lint.Report, failOn lint.Severity) int {
+	if report.FileErrorCount > 0 {
+		return 2
+	}
+	if report.CountAtLeast(failOn) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printSummary(report *lint.Report) {
+	if report.FileErrorCount == 0 && report.ErrorCount == 0 {
+		return
+	}
+	fmt.Fprintf(report.Writer, "%d files failed to read, %d lint findings\n", report.FileErrorCount, report.ErrorCount)
+}
+
+// listRules writes a table of the rules to writer: their names, default
+// severities, whether they are opt-in or only for complete documents, and
+// titles.
+func listRules(writer io.Writer) error {
+	table := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+	for _, rule := range lint.Rules() {
+		var flags []string
+		if rule.OptIn {
+			flags = append(flags, "opt-in")
+		}
+		if rule.Document {
+			flags = append(flags, "document")
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", rule.Name, rule.Severity, strings.Join(flags, ","), rule.Title)
+	}
+	return table.Flush()
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func setEnabled(options *lint.Options, names string, enabled bool) error {
+	if options.Enabled == nil {
+		options.Enabled = map[string]bool{}
+	}
+	for _, name := range splitList(names) {
+		if e := lint.CheckRuleName(name); e != nil {
+			return e
+		}
+		options.Enabled[name] = enabled
+	}
+	return nil
+}
+
+func setSeverity(options *lint.Options, list string) error {
+	if options.Severity == nil {
+		options.Severity = map[string]string{}
+	}
+	for _, item := range splitList(list) {
+		name, severity, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("%q is not rule=severity", item)
+		}
+		if e := lint.CheckRuleName(name); e != nil {
+			return e
+		}
+		if _, e := lint.ParseSeverity(severity); e != nil && severity != lint.SeverityOff {
+			return e
+		}
+		options.Severity[name] = severity
+	}
+	return nil
+}
+
+// settings holds the command line flags that are not Options.
+type settings struct {
+	timeout       time.Duration
+	failOn        string
+	baseline      string
+	writeBaseline bool
+	diff          string
+
+	fragment        bool
+	fragmentContext string
+
+	serve     bool
+	watch     bool
+	listRules bool
+}
+
+// newFlagSet returns a FlagSet that stores flags in options and settings. The
+// command parses its arguments once, and then again on top of the Options for
+// each directory, so that the command line overrides configuration files.
+func newFlagSet(options *lint.Options, settings *settings) *flag.FlagSet {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flags.DurationVar(&settings.timeout, "timeout", 0, "stop linting after this long (default no limit)")
+	flags.StringVar(&settings.failOn, "fail-on", "warning", "least severe finding (error, warning, or info) that causes a non-zero exit status")
+	flags.StringVar(&settings.baseline, "baseline", "", "suppress the findings in this baseline file, reporting only new ones")
+	flags.BoolVar(&settings.writeBaseline, "write-baseline", false, "write all findings to the -baseline file, rather than suppressing them")
+	flags.StringVar(&settings.diff, "diff", "", "report only findings on the lines changed by this unified diff file (- for the standard input)")
+	flags.BoolVar(&settings.fragment, "fragment", false, "lint fragments, such as template partials, rather than complete documents")
+	flags.StringVar(&settings.fragmentContext, "fragment-context", "body", "element that -fragment fragments appear in")
+	flags.BoolVar(&settings.serve, "serve", false, "serve lint requests on the standard input and output, for editors (see above)")
+	flags.BoolVar(&settings.watch, "watch", false, "after linting, lint files again whenever they change, until interrupted")
+	flags.BoolVar(&settings.listRules, "list-rules", false, "list the rules, with their default severities, and exit")
+	flags.Func("enable", "comma-separated list of rules to enable", func(names string) error {
+		return setEnabled(options, names, true)
+	})
+	flags.Func("disable", "comma-separated list of rules to disable", func(names string) error {
+		return setEnabled(options, names, false)
+	})
+	flags.Func("severity", "comma-separated list of rule=severity overrides, where severity is error, warning, info, or off", func(list string) error {
+		return setSeverity(options, list)
+	})
+	flags.BoolFunc("check-images", "check that <img> width and height match the aspect ratio of local image files (same as -enable aspect-ratio)", func(string) error {
+		return setEnabled(options, "aspect-ratio", true)
+	})
+	listVar := func(list *[]string, name, usage string) {
+		flags.Func(name, usage+" (default "+strings.Join(*list, ",")+")", func(value string) error {
+			*list = splitList(value)
+			return nil
+		})
+	}
+
+	flags.IntVar(&options.MetaDescriptionMinLength, "meta-description-min-length", options.MetaDescriptionMinLength, "minimum length of <meta name=description> content")
+	flags.IntVar(&options.MetaDescriptionMaxLength, "meta-description-max-length", options.MetaDescriptionMaxLength, "maximum length of <meta name=description> content")
+	listVar(&options.OpenGraphRequired, "open-graph-required", "comma-separated list of og:* properties required by the open-graph rule")
+	listVar(&options.TwitterCardRequired, "twitter-card-required", "comma-separated list of twitter:* properties required by the open-graph rule")
+	flags.BoolVar(&options.RequireAppleTouchIcon, "require-apple-touch-icon", options.RequireAppleTouchIcon, "make the favicon rule also require <link rel=apple-touch-icon>")
+	flags.BoolVar(&options.RequireMain, "require-main", options.RequireMain, "make the main-landmark rule also require a <main>")
+	flags.Float64Var(&options.AspectRatioTolerance, "aspect-ratio-tolerance", options.AspectRatioTolerance, "relative aspect ratio difference allowed by -check-images")
+	listVar(&options.HTTPSHosts, "https-hosts", "comma-separated list of hosts known to support HTTPS, to which http: links are reported")
+	flags.IntVar(&options.MaxCodeLineLength, "max-code-line-length", options.MaxCodeLineLength, "longest line allowed in <pre> and <code> by the code-line-length rule")
+	flags.StringVar(&options.Indentation, "indentation", options.Indentation, "indentation style, spaces or tabs, required by the source-indentation rule")
+	flags.IntVar(&options.MaxBlockingStylesheets, "max-blocking-stylesheets", options.MaxBlockingStylesheets, "number of render-blocking stylesheets allowed by the stylesheet-loading rule")
+	flags.IntVar(&options.HeadingWordThreshold, "heading-word-threshold", options.HeadingWordThreshold, "number of words <body> may have without a heading under the no-headings rule")
+	flags.StringVar(&options.LineEndings, "line-endings", options.LineEndings, "line ending style, lf or crlf, required by the line-endings rule (default any consistent style)")
+	flags.IntVar(&options.TableSectionRows, "table-section-rows", options.TableSectionRows, "number of rows a <table> may have without <thead> or <tbody> under the table-sections rule")
+	flags.IntVar(&options.MaxDepth, "max-depth", options.MaxDepth, "deepest nesting to lint, beyond which the document is reported (0 for no limit)")
+	listVar(&options.CommentMarkers, "comment-markers", "comma-separated list of words reported in comments by the comment-markers rule")
+	flags.IntVar(&options.CommentedCodeMinLength, "commented-code-min-length", options.CommentedCodeMinLength, "length a comment with markup must exceed to be reported by the commented-code rule")
+	listVar(&options.TimeLayouts, "time-layouts", "comma-separated list of Go time layouts that <time> text may have without a datetime attribute")
+	listVar(&options.GenericLinkText, "generic-link-text", "comma-separated list of link texts reported by the link-text rule")
+	listVar(&options.URLAttributes, "url-attributes", "comma-separated list of attributes checked by the url-syntax rule")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), helpMessage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// optionsLoader loads the Options for each directory once. args are the
+// command line arguments, which override the configuration files.
+type optionsLoader struct {
+	cache map[string]*lint.Options
+	args  []string
+}
+
+// load returns the Options for pathname: those of the configuration files in
+// its directory and ancestors, overridden by the command line.
+func (l *optionsLoader) load(pathname string) (*lint.Options, error) {
+	directory := filepath.Dir(pathname)
+	if options, ok := l.cache[directory]; ok {
+		return options, nil
+	}
+	options, e := lint.LoadOptions(lint.DefaultOptions(), pathname)
+	if e != nil {
+		return nil, e
+	}
+	if e := newFlagSet(options, &settings{}).Parse(l.args); e != nil {
+		return nil, e
+	}
+	l.cache[directory] = options
+	return options, nil
+}
+
+func lintFile(ctx context.Context, report *lint.Report, loader *optionsLoader, pathname, fragmentContext string) {
+	options, e := loader.load(pathname)
+	if e != nil {
+		report.FileError(e)
+		return
+	}
+	report.Options = options
+	reader, e := os.Open(pathname)
+	if e != nil {
+		report.FileError(e)
+		return
+	}
+	defer reader.Close()
+	if e := lintReader(ctx, report, reader, pathname, fragmentContext); e != nil {
+		report.FileError(pathname, e)
+	}
+}
+
+// gzipMagic is the first 2 bytes of gzip data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// lintReader lints the document in reader, decompressing it first if it is
+// gzipped, such as a .html.gz file. If fragmentContext is not empty, the
+// document is a fragment in a fragmentContext element.
+func lintReader(ctx context.Context, report *lint.Report, reader io.Reader, pathname, fragmentContext string) error {
+	buffered := bufio.NewReader(reader)
+	if magic, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		decompressor, e := gzip.NewReader(buffered)
+		if e != nil {
+			return e
+		}
+		defer decompressor.Close()
+		reader = decompressor
+	} else {
+		reader = buffered
+	}
+	if fragmentContext != "" {
+		return lint.LintFragmentReaderContext(ctx, report, reader, pathname, fragmentContext)
+	}
+	return lint.LintReaderContext(ctx, report, reader, pathname)
+}
+
+func readBaseline(pathname string) (*lint.Baseline, error) {
+	file, e := os.Open(pathname)
+	if e != nil {
+		return nil, e
+	}
+	defer file.Close()
+	return lint.ReadBaseline(file)
+}
+
+func writeBaseline(pathname string, findings []lint.Finding) error {
+	if pathname == "" {
+		return fmt.Errorf("-write-baseline requires -baseline")
+	}
+	file, e := os.Create(pathname)
+	if e != nil {
+		return e
+	}
+	if e := lint.WriteBaseline(file, findings); e != nil {
+		file.Close()
+		return e
+	}
+	return file.Close()
+}
+
+func readDiff(pathname string) (lint.ChangedLines, error) {
+	if pathname == "-" {
+		return lint.ParseDiff(os.Stdin)
+	}
+	file, e := os.Open(pathname)
+	if e != nil {
+		return nil, e
+	}
+	defer file.Close()
+	return lint.ParseDiff(file)
+}
+
+// diffPathnames returns the pathnames of the HTML files in changed.
+func diffPathnames(changed lint.ChangedLines) []string {
+	var pathnames []string
+	for _, pathname := range changed.Pathnames() {
+		if lint.IsHTMLFile(pathname) {
+			pathnames = append(pathnames, pathname)
+		}
+	}
+	return pathnames
+}
+
 func main() {
-	flag.Usage = func() {
-		fmt.Fprintln(flag.CommandLine.Output(), helpMessage)
+	var settings settings
+	flags := newFlagSet(lint.DefaultOptions(), &settings)
+	flags.Parse(os.Args[1:])
+	failOn, e := lint.ParseSeverity(settings.failOn)
+	if e != nil {
+		fmt.Fprintln(os.Stderr, e)
+		os.Exit(2)
+	}
+	if settings.watch && flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "-watch requires files or directories")
+		os.Exit(2)
 	}
-	flag.Parse()
 
-	report := lint.Report{Writer: os.Stderr, ErrorCount: 0}
+	if settings.listRules {
+		if e := listRules(os.Stdout); e != nil {
+			fmt.Fprintln(os.Stderr, e)
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
 
-	for _, pathname := range flag.Args() {
-		reader, e := os.Open(pathname)
+	report := lint.Report{Writer: os.Stderr}
+	if settings.baseline != "" && !settings.writeBaseline {
+		baseline, e := readBaseline(settings.baseline)
 		if e != nil {
-			report.Println(e)
-			continue
+			fmt.Fprintln(os.Stderr, e)
+			os.Exit(2)
+		}
+		report.Filter = baseline.Filter
+	}
+	pathnames := flags.Args()
+	if settings.diff != "" {
+		changed, e := readDiff(settings.diff)
+		if e != nil {
+			fmt.Fprintln(os.Stderr, e)
+			os.Exit(2)
+		}
+		if baseline := report.Filter; baseline != nil {
+			report.Filter = func(f lint.Finding) bool { return changed.Filter(f) && baseline(f) }
+		} else {
+			report.Filter = changed.Filter
+		}
+		if len(pathnames) == 0 {
+			pathnames = diffPathnames(changed)
+		}
+	}
+	loader := &optionsLoader{cache: map[string]*lint.Options{}, args: os.Args[1:]}
+	fragmentContext := ""
+	if settings.fragment {
+		fragmentContext = settings.fragmentContext
+	}
+
+	ctx := context.Background()
+	if settings.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, settings.timeout)
+		defer cancel()
+	}
+
+	if settings.serve {
+		if e := serve(os.Stdin, os.Stdout, loader, settings.timeout, fragmentContext); e != nil {
+			fmt.Fprintln(os.Stderr, e)
+			os.Exit(2)
 		}
-		defer reader.Close()
+		os.Exit(0)
+	}
 
-		document, e := html.Parse(reader)
+	for _, pathname := range pathnames {
+		if ctx.Err() != nil {
+			break
+		}
+		info, e := os.Stat(pathname)
 		if e != nil {
-			report.Println(e)
+			report.FileError(e)
 			continue
 		}
-		lint.Lint(&report, document, pathname)
-		if _, e := reader.Seek(0, 0); e != nil {
-			report.Println(e)
+		if !info.IsDir() {
+			lintFile(ctx, &report, loader, pathname, fragmentContext)
 			continue
 		}
-		lint.LintNesting(&report, reader, pathname)
+		pathnames, e := lint.FindHTMLFiles(ctx, pathname)
+		if e != nil {
+			report.FileError(e)
+		}
+		for _, p := range pathnames {
+			lintFile(ctx, &report, loader, p, fragmentContext)
+		}
 	}
-	if len(flag.Args()) == 0 {
-		document, e := html.Parse(os.Stdin)
+	if flags.NArg() == 0 && settings.diff == "" {
+		options, e := loader.load("<stdin>")
 		if e != nil {
-			report.Println(e)
-			os.Exit(report.ErrorCount)
+			report.FileError(e)
+		} else {
+			report.Options = options
+			if e := lintReader(ctx, &report, os.Stdin, "<stdin>", fragmentContext); e != nil {
+				report.FileError("<stdin>", e)
+			}
+		}
+	}
+	if settings.writeBaseline {
+		if e := writeBaseline(settings.baseline, report.Findings); e != nil {
+			fmt.Fprintln(os.Stderr, e)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "wrote", len(report.Findings), "findings to", settings.baseline)
+		if report.FileErrorCount > 0 {
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+	printSummary(&report)
+	if settings.watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if e := watch(ctx, os.Stderr, flags.Args(), loader, fragmentContext); e != nil {
+			fmt.Fprintln(os.Stderr, e)
+			os.Exit(2)
 		}
-		lint.Lint(&report, document, "<stdin>")
+		os.Exit(0)
 	}
-	os.Exit(report.ErrorCount)
+	os.Exit(exitStatus(&report, failOn))
 }
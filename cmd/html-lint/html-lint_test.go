@@ -0,0 +1,64 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lint "github.com/noncombatant/html_lint"
+)
+
+func TestLintFileGzip(t *testing.T) {
+	root := t.TempDir()
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("<p>Goats</p>\n<p><a name=\"goat\">Goat</a></p>\n"))
+	if e := writer.Close(); e != nil {
+		t.Fatal(e)
+	}
+	good := filepath.Join(root, "good.html.gz")
+	truncated := filepath.Join(root, "truncated.html.gz")
+	for pathname, data := range map[string][]byte{
+		good:      compressed.Bytes(),
+		truncated: compressed.Bytes()[:compressed.Len()/2],
+	} {
+		if e := os.WriteFile(pathname, data, 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	pathnames, e := lint.FindHTMLFiles(context.Background(), root)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(pathnames) != 2 {
+		t.Errorf("received %q, expected the 2 .html.gz files", pathnames)
+	}
+
+	loader := &optionsLoader{cache: map[string]*lint.Options{}, args: []string{"-disable", "meta-description,doctype"}}
+	var output strings.Builder
+	report := lint.Report{Writer: &output}
+	lintFile(context.Background(), &report, loader, good, "")
+	if report.ErrorCount != 1 || report.FileErrorCount != 0 || !strings.Contains(output.String(), good+":2 <a> has name; should use id") {
+		t.Errorf("received %d findings and %d file errors: %q", report.ErrorCount, report.FileErrorCount, output.String())
+	}
+
+	output.Reset()
+	report = lint.Report{Writer: &output}
+	lintFile(context.Background(), &report, loader, truncated, "")
+	if report.FileErrorCount != 1 || !strings.Contains(output.String(), "unexpected EOF") {
+		t.Errorf("received %d file errors: %q", report.FileErrorCount, output.String())
+	}
+
+	output.Reset()
+	report = lint.Report{Writer: &output}
+	if e := lintReader(context.Background(), &report, bytes.NewReader([]byte{0x1f, 0x8b, 'g', 'o', 'a', 't'}), "corrupt.html.gz", ""); e == nil {
+		t.Errorf("expected an error for a corrupt gzip header")
+	}
+}
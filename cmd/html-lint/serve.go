@@ -0,0 +1,90 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	lint "github.com/noncombatant/html_lint"
+)
+
+// serveRequest is a request in the -serve protocol: a document to lint.
+type serveRequest struct {
+	Path string `json:"path"`
+	Text string `json:"text"`
+}
+
+// serveResponse is the response to a serveRequest. Findings is never null, so
+// that clients can always iterate it.
+type serveResponse struct {
+	Path     string         `json:"path"`
+	Findings []lint.Finding `json:"findings"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// maxRequestSize is the size in bytes of the longest line that serve accepts.
+const maxRequestSize = 64 << 20
+
+// serve reads serveRequests from r, one per line, and writes a serveResponse
+// for each to w, until r ends. A line that is not a valid request gets a
+// response with only an Error. Each request may take at most timeout, if it
+// is positive.
+func serve(r io.Reader, w io.Writer, loader *optionsLoader, timeout time.Duration, fragmentContext string) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRequestSize)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	// Reuse the reader and the findings slice across requests; each response
+	// is written before the next request is read.
+	var text strings.Reader
+	findings := []lint.Finding{}
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var request serveRequest
+		response := serveResponse{Findings: findings[:0]}
+		if e := json.Unmarshal(line, &request); e != nil {
+			response.Error = e.Error()
+		} else {
+			response.Path = request.Path
+			text.Reset(request.Text)
+			if e := serveOne(&response, &request, &text, loader, timeout, fragmentContext); e != nil {
+				response.Error = e.Error()
+			}
+		}
+		if e := encoder.Encode(&response); e != nil {
+			return e
+		}
+		findings = response.Findings
+	}
+	return scanner.Err()
+}
+
+// serveOne lints text, the document in request, appending the findings to
+// response.
+func serveOne(response *serveResponse, request *serveRequest, text io.Reader, loader *optionsLoader, timeout time.Duration, fragmentContext string) error {
+	options, e := loader.load(request.Path)
+	if e != nil {
+		return e
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	report := lint.Report{Writer: io.Discard, Options: options, Findings: response.Findings}
+	e = lintReader(ctx, &report, text, request.Path, fragmentContext)
+	response.Findings = report.Findings
+	return e
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lint "github.com/noncombatant/html_lint"
+)
+
+func TestServe(t *testing.T) {
+	root := t.TempDir()
+	if e := os.Mkdir(filepath.Join(root, "bad"), 0o755); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.WriteFile(filepath.Join(root, "bad", lint.ConfigFileName), []byte(`{"enabled": {"goats": true}}`), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	good := filepath.Join(root, "good.html")
+	bad := filepath.Join(root, "bad", "page.html")
+	requests := strings.Join([]string{
+		`{"path": "` + good + `", "text": "<p>Goats</p>\n"}`,
+		`{"path": "` + good + `", "text": "<p>Goats</p>\n<p><a name=\"goat\">Goat</a></p>\n"}`,
+		`{"path": "` + bad + `", "text": "<p>Goats</p>\n"}`,
+		`{"path": `,
+		``,
+		`{"path": "` + good + `", "text": "<p>Goats</p>\n"}`,
+	}, "\n")
+
+	var output bytes.Buffer
+	loader := &optionsLoader{cache: map[string]*lint.Options{}, args: []string{"-disable", "meta-description,doctype"}}
+	if e := serve(strings.NewReader(requests), &output, loader, 0, ""); e != nil {
+		t.Fatal(e)
+	}
+
+	responses := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	expected := []string{
+		`{"path":"` + good + `","findings":[]}`,
+		`{"path":"` + good + `","findings":[{"pathname":"` + good + `","line":2,"rule":"a-name","severity":"error","message":"<a> has name; should use id"}]}`,
+		`{"path":"` + bad + `","findings":[],"error":"` + filepath.Join(root, "bad", lint.ConfigFileName) + `: unknown rule: goats"}`,
+		`{"path":"","findings":[],"error":"unexpected end of JSON input"}`,
+		`{"path":"` + good + `","findings":[]}`,
+	}
+	if len(responses) != len(expected) {
+		t.Fatalf("received %d responses, expected %d: %q", len(responses), len(expected), responses)
+	}
+	for i := range expected {
+		if responses[i] != expected[i] {
+			t.Errorf("received %s, expected %s", responses[i], expected[i])
+		}
+	}
+}
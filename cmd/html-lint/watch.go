@@ -0,0 +1,172 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	lint "github.com/noncombatant/html_lint"
+)
+
+// watchDelay is how long watch waits after a file changes before linting it,
+// so that a burst of writes from an editor causes only 1 lint.
+const watchDelay = 100 * time.Millisecond
+
+// watchedFiles returns the set of HTML files in roots, which are files or
+// directories, skipping those that FindHTMLFiles would.
+func watchedFiles(ctx context.Context, roots []string) map[string]bool {
+	files := map[string]bool{}
+	for _, root := range roots {
+		info, e := os.Stat(root)
+		if e != nil {
+			continue
+		}
+		if !info.IsDir() {
+			files[filepath.Clean(root)] = true
+			continue
+		}
+		pathnames, _ := lint.FindHTMLFiles(ctx, root)
+		for _, p := range pathnames {
+			files[filepath.Clean(p)] = true
+		}
+	}
+	return files
+}
+
+// addTree adds root and, if it is a directory, the directories in it that
+// FindHTMLFiles does not skip to watcher. fsnotify does not watch directories
+// recursively. Adding a directory that is already watched does nothing.
+func addTree(ctx context.Context, watcher *fsnotify.Watcher, root string) error {
+	info, e := os.Stat(root)
+	if e != nil {
+		return e
+	}
+	if !info.IsDir() {
+		return watcher.Add(filepath.Dir(root))
+	}
+	directories, e := lint.FindDirectories(ctx, root)
+	if e != nil {
+		return e
+	}
+	for _, d := range directories {
+		if e := watcher.Add(d); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// newWatcher returns a watcher of the directories in roots.
+func newWatcher(ctx context.Context, roots []string) (*fsnotify.Watcher, error) {
+	watcher, e := fsnotify.NewWatcher()
+	if e != nil {
+		return nil, e
+	}
+	for _, root := range roots {
+		if e := addTree(ctx, watcher, root); e != nil {
+			watcher.Close()
+			return nil, e
+		}
+	}
+	return watcher, nil
+}
+
+// watch lints the HTML files in roots again whenever they change, until ctx
+// is done. Before each file's findings, it prints a line marking them as the
+// file's current findings, replacing any printed before.
+func watch(ctx context.Context, w io.Writer, roots []string, loader *optionsLoader, fragmentContext string) error {
+	watcher, e := newWatcher(ctx, roots)
+	if e != nil {
+		return e
+	}
+	defer watcher.Close()
+	return watchEvents(ctx, w, watcher, roots, loader, fragmentContext)
+}
+
+// watchEvents relints the files that watcher reports changes to, once they
+// have been quiet for watchDelay, until ctx is done.
+func watchEvents(ctx context.Context, w io.Writer, watcher *fsnotify.Watcher, roots []string, loader *optionsLoader, fragmentContext string) error {
+	pending := map[string]bool{}
+	// quiet receives when watchDelay has passed since the last event. Each
+	// event replaces it, so that creates, writes, and renames in a burst are
+	// linted together, once.
+	var quiet <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(w, e)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Rename) && !event.Has(fsnotify.Remove) {
+				continue
+			}
+			name := filepath.Clean(event.Name)
+			pending[name] = true
+			if info, e := os.Stat(name); e == nil && info.IsDir() && event.Has(fsnotify.Create) {
+				// Files may have been created in the new directory before
+				// it was watched.
+				for _, root := range roots {
+					if e := addTree(ctx, watcher, root); e != nil {
+						fmt.Fprintln(w, e)
+					}
+				}
+				for pathname := range watchedFiles(ctx, roots) {
+					if strings.HasPrefix(pathname, name+string(filepath.Separator)) {
+						pending[pathname] = true
+					}
+				}
+			}
+			quiet = time.After(watchDelay)
+		case <-quiet:
+			relint(ctx, w, roots, pending, loader, fragmentContext)
+			pending = map[string]bool{}
+			quiet = nil
+		}
+	}
+}
+
+// relint lints the watched files among changed.
+func relint(ctx context.Context, w io.Writer, roots []string, changed map[string]bool, loader *optionsLoader, fragmentContext string) {
+	watched := watchedFiles(ctx, roots)
+	var pathnames []string
+	for pathname := range changed {
+		if filepath.Base(pathname) == lint.ConfigFileName {
+			loader.cache = map[string]*lint.Options{}
+		}
+		pathnames = append(pathnames, pathname)
+	}
+	sort.Strings(pathnames)
+	for _, pathname := range pathnames {
+		if _, e := os.Stat(pathname); e != nil {
+			if lint.IsHTMLFile(pathname) {
+				fmt.Fprintln(w, "---", pathname, "removed")
+			}
+			continue
+		}
+		if !watched[pathname] {
+			continue
+		}
+		fmt.Fprintln(w, "---", pathname, time.Now().Format(time.TimeOnly))
+		report := lint.Report{Writer: w}
+		lintFile(ctx, &report, loader, pathname, fragmentContext)
+		if report.ErrorCount == 0 && report.FileErrorCount == 0 {
+			fmt.Fprintln(w, "no lint findings")
+		}
+	}
+}
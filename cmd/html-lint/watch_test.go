@@ -0,0 +1,87 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	lint "github.com/noncombatant/html_lint"
+)
+
+func TestWatch(t *testing.T) {
+	root := t.TempDir()
+	if e := os.MkdirAll(filepath.Join(root, "ignored"), 0o755); e != nil {
+		t.Fatal(e)
+	}
+	for pathname, text := range map[string]string{
+		lint.IgnoreFileName: "ignored/\n",
+		"a.html":            "<p>Goats</p>\n",
+	} {
+		if e := os.WriteFile(filepath.Join(root, pathname), []byte(text), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	roots := []string{root}
+	watcher, e := newWatcher(ctx, roots)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer watcher.Close()
+	if slices.Contains(watcher.WatchList(), filepath.Join(root, "ignored")) {
+		t.Errorf("watching ignored directory")
+	}
+
+	var output bytes.Buffer
+	loader := &optionsLoader{cache: map[string]*lint.Options{}, args: []string{"-disable", "meta-description,doctype"}}
+	done := make(chan error)
+	go func() {
+		done <- watchEvents(ctx, &output, watcher, roots, loader, "")
+	}()
+
+	// A burst of creates and writes, including of a file in a new directory,
+	// is linted once.
+	if e := os.WriteFile(filepath.Join(root, "a.html"), []byte("<p><a name=goat>Goats</a></p>\n"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.Mkdir(filepath.Join(root, "new"), 0o755); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.WriteFile(filepath.Join(root, "new", "b.html"), []byte("<p>Goats</p>\n"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.WriteFile(filepath.Join(root, "ignored", "c.html"), []byte("<p>Goats</p>\n"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	time.Sleep(10 * watchDelay)
+	cancel()
+	if e := <-done; e != nil {
+		t.Fatal(e)
+	}
+
+	received := output.String()
+	for _, c := range []struct {
+		text  string
+		count int
+	}{
+		{"--- " + filepath.Join(root, "a.html"), 1},
+		{"<a> has name; should use id", 1},
+		{"--- " + filepath.Join(root, "new", "b.html"), 1},
+		{"no lint findings", 1},
+		{"c.html", 0},
+	} {
+		if n := strings.Count(received, c.text); n != c.count {
+			t.Errorf("received %q %d times, expected %d, in %q", c.text, n, c.count, received)
+		}
+	}
+}
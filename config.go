@@ -0,0 +1,80 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ConfigFileName is the name of the JSON files from which LoadOptions reads
+// Options.
+const ConfigFileName = ".htmllint.json"
+
+// LoadOptions returns a copy of base, as overridden by the ConfigFileName
+// files in the directory of pathname and in each of its ancestors.
+//
+// Nearer files take precedence: LoadOptions applies the file in the root
+// directory first and the file in pathname's own directory last. A file
+// overrides only the options that it sets. Lists replace the lists of farther
+// files, but "enabled" and "severity" are merged rule by rule, so that a
+// subdirectory can enable, disable, or change the severity of a rule without
+// restating the other rules. It is an error for a file to name a rule or
+// severity that does not exist.
+func LoadOptions(base *Options, pathname string) (*Options, error) {
+	options, e := cloneOptions(base)
+	if e != nil {
+		return nil, e
+	}
+
+	directory, e := filepath.Abs(filepath.Dir(pathname))
+	if e != nil {
+		return nil, e
+	}
+	var configs []string
+	for {
+		configs = append(configs, filepath.Join(directory, ConfigFileName))
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			break
+		}
+		directory = parent
+	}
+
+	for i := len(configs) - 1; i >= 0; i-- {
+		data, e := os.ReadFile(configs[i])
+		if errors.Is(e, fs.ErrNotExist) {
+			continue
+		}
+		if e != nil {
+			return nil, e
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if e := decoder.Decode(options); e != nil {
+			return nil, fmt.Errorf("%s: %w", configs[i], e)
+		}
+		if e := options.check(); e != nil {
+			return nil, fmt.Errorf("%s: %w", configs[i], e)
+		}
+	}
+	return options, nil
+}
+
+func cloneOptions(options *Options) (*Options, error) {
+	data, e := json.Marshal(options)
+	if e != nil {
+		return nil, e
+	}
+	var clone Options
+	if e := json.Unmarshal(data, &clone); e != nil {
+		return nil, e
+	}
+	return &clone, nil
+}
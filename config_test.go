@@ -0,0 +1,82 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadOptions(t *testing.T) {
+	root := t.TempDir()
+	if e := os.MkdirAll(filepath.Join(root, "a", "b"), 0o755); e != nil {
+		t.Fatal(e)
+	}
+	for pathname, config := range map[string]string{
+		ConfigFileName:                          `{"max-code-line-length": 100, "https-hosts": ["example.com"], "enabled": {"favicon": true, "dashes": true}, "severity": {"alt-text": "warning", "a-name": "info"}}`,
+		filepath.Join("a", "b", ConfigFileName): `{"max-code-line-length": 72, "enabled": {"dashes": false, "curly-quotes": false}, "severity": {"a-name": "off"}}`,
+	} {
+		if e := os.WriteFile(filepath.Join(root, pathname), []byte(config), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	base := DefaultOptions()
+	options, e := LoadOptions(base, filepath.Join(root, "a", "b", "page.html"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if options.MaxCodeLineLength != 72 {
+		t.Errorf("received MaxCodeLineLength %d, expected 72", options.MaxCodeLineLength)
+	}
+	if !reflect.DeepEqual(options.HTTPSHosts, []string{"example.com"}) {
+		t.Errorf("received HTTPSHosts %q", options.HTTPSHosts)
+	}
+	expected := map[string]bool{"favicon": true, "dashes": false, "curly-quotes": false}
+	if !reflect.DeepEqual(options.Enabled, expected) {
+		t.Errorf("received Enabled %v, expected %v", options.Enabled, expected)
+	}
+	expectedSeverity := map[string]string{"alt-text": "warning", "a-name": SeverityOff}
+	if !reflect.DeepEqual(options.Severity, expectedSeverity) {
+		t.Errorf("received Severity %v, expected %v", options.Severity, expectedSeverity)
+	}
+	if options.MetaDescriptionMinLength != base.MetaDescriptionMinLength {
+		t.Errorf("received MetaDescriptionMinLength %d, expected %d", options.MetaDescriptionMinLength, base.MetaDescriptionMinLength)
+	}
+	if base.Enabled != nil || base.MaxCodeLineLength != 80 {
+		t.Errorf("LoadOptions modified base")
+	}
+
+	options, e = LoadOptions(base, filepath.Join(root, "a", "page.html"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if options.MaxCodeLineLength != 100 || !options.Enabled["dashes"] {
+		t.Errorf("received MaxCodeLineLength %d and Enabled %v", options.MaxCodeLineLength, options.Enabled)
+	}
+
+	if e := os.WriteFile(filepath.Join(root, "a", ConfigFileName), []byte(`{"max-code-line-lenght": 1}`), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	if _, e := LoadOptions(base, filepath.Join(root, "a", "page.html")); e == nil {
+		t.Errorf("expected an error for an unknown option")
+	}
+
+	if e := os.WriteFile(filepath.Join(root, "a", ConfigFileName), []byte(`{"severity": {"dashes": "loud"}}`), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	if _, e := LoadOptions(base, filepath.Join(root, "a", "page.html")); e == nil {
+		t.Errorf("expected an error for an unknown severity")
+	}
+
+	if e := os.WriteFile(filepath.Join(root, "a", ConfigFileName), []byte(`{"enabled": {"img-fig": false}}`), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	if _, e := LoadOptions(base, filepath.Join(root, "a", "page.html")); e == nil || !strings.HasSuffix(e.Error(), "unknown rule: img-fig; did you mean img-figure?") {
+		t.Errorf("received %v, expected an error for an unknown rule", e)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hunkHeader matches the header of a unified diff hunk, capturing the start
+// and length of the old side, and then of the new side.
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// A LineRange is an inclusive range of lines.
+type LineRange struct {
+	First, Last int
+}
+
+// ChangedLines maps pathnames to the ranges of lines that a diff adds or
+// changes in them.
+type ChangedLines map[string][]LineRange
+
+// ParseDiff reads a unified diff, such as the output of `git diff`, and
+// returns the lines that its hunks add on the new side. Context lines around
+// the changes are omitted, as are deleted files.
+func ParseDiff(r io.Reader) (ChangedLines, error) {
+	changed := ChangedLines{}
+	pathname := ""
+	// line is the next line on the new side, and oldLeft and newLeft count
+	// the lines remaining in the hunk on each side.
+	line, oldLeft, newLeft := 0, 0, 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if pathname != "" {
+					changed.add(pathname, line)
+				}
+				line, newLeft = line+1, newLeft-1
+			case strings.HasPrefix(text, "-"):
+				oldLeft--
+			case strings.HasPrefix(text, "\\"):
+				// "\ No newline at end of file"
+			default:
+				line, oldLeft, newLeft = line+1, oldLeft-1, newLeft-1
+			}
+			continue
+		}
+		if strings.HasPrefix(text, "+++ ") {
+			pathname = strings.TrimPrefix(text, "+++ ")
+			if i := strings.IndexByte(pathname, '\t'); i >= 0 {
+				pathname = pathname[:i]
+			}
+			if pathname == "/dev/null" {
+				pathname = ""
+			} else {
+				pathname = filepath.Clean(strings.TrimPrefix(pathname, "b/"))
+			}
+			continue
+		}
+		match := hunkHeader.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		numbers := make([]int, 4)
+		for i, m := range match[1:] {
+			numbers[i] = 1
+			if m == "" {
+				continue
+			}
+			n, e := strconv.Atoi(m)
+			if e != nil {
+				return nil, fmt.Errorf("bad hunk header %q: %w", text, e)
+			}
+			numbers[i] = n
+		}
+		line, oldLeft, newLeft = numbers[2], numbers[1], numbers[3]
+	}
+	return changed, scanner.Err()
+}
+
+// add adds line to the changed lines of pathname, extending the last range if
+// line follows it.
+func (c ChangedLines) add(pathname string, line int) {
+	ranges := c[pathname]
+	if n := len(ranges); n > 0 && ranges[n-1].Last == line-1 {
+		ranges[n-1].Last = line
+		return
+	}
+	c[pathname] = append(ranges, LineRange{line, line})
+}
+
+// Pathnames returns the pathnames of the changed files, sorted.
+func (c ChangedLines) Pathnames() []string {
+	var pathnames []string
+	for pathname := range c {
+		pathnames = append(pathnames, pathname)
+	}
+	sort.Strings(pathnames)
+	return pathnames
+}
+
+// ranges returns the changed ranges of pathname, which may be longer than the
+// pathname in the diff, such as when the diff is relative to a subdirectory.
+func (c ChangedLines) ranges(pathname string) []LineRange {
+	pathname = filepath.Clean(pathname)
+	if ranges, ok := c[pathname]; ok {
+		return ranges
+	}
+	for p, ranges := range c {
+		if strings.HasSuffix(pathname, string(filepath.Separator)+p) {
+			return ranges
+		}
+	}
+	return nil
+}
+
+// Filter returns true if f is on a changed line. It is suitable for use as
+// Report.Filter. Findings whose line is not known are dropped.
+func (c ChangedLines) Filter(f Finding) bool {
+	for _, r := range c.ranges(f.Pathname) {
+		if f.Line >= r.First && f.Line <= r.Last {
+			return true
+		}
+	}
+	return false
+}
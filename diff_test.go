@@ -0,0 +1,98 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDiff = `diff --git a/site/goat.html b/site/goat.html
+index 1111111..2222222 100644
+--- a/site/goat.html
++++ b/site/goat.html
+@@ -3,2 +3,4 @@ <body>
+ <p>Goats</p>
++<img src="goat.jpg">
++<img src="kid.jpg">
+ </body>
+@@ -20 +22 @@
+-<p>old</p>
++<p>new</p>
+@@ -30,3 +31,0 @@
+-<p>gone</p>
+diff --git a/old.html b/old.html
+deleted file mode 100644
+--- a/old.html
++++ /dev/null
+@@ -1,2 +0,0 @@
+-<p>old</p>
+`
+
+func TestParseDiff(t *testing.T) {
+	changed, e := ParseDiff(strings.NewReader(testDiff))
+	if e != nil {
+		t.Fatal(e)
+	}
+	expected := ChangedLines{"site/goat.html": {{4, 5}, {22, 22}}}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("received %v, expected %v", changed, expected)
+	}
+	if pathnames := changed.Pathnames(); !reflect.DeepEqual(pathnames, []string{"site/goat.html"}) {
+		t.Errorf("received pathnames %v", pathnames)
+	}
+
+	for _, c := range []struct {
+		finding  Finding
+		expected bool
+	}{
+		{Finding{Pathname: "site/goat.html", Line: 4}, true},
+		{Finding{Pathname: "./site/goat.html", Line: 22}, true},
+		{Finding{Pathname: "/home/me/site/goat.html", Line: 5}, true},
+		{Finding{Pathname: "site/goat.html", Line: 3}, false},
+		{Finding{Pathname: "site/goat.html", Line: 6}, false},
+		{Finding{Pathname: "site/goat.html"}, false},
+		{Finding{Pathname: "mysite/goat.html", Line: 4}, false},
+		{Finding{Pathname: "old.html", Line: 1}, false},
+	} {
+		if received := changed.Filter(c.finding); received != c.expected {
+			t.Errorf("%v: received %v, expected %v", c.finding, received, c.expected)
+		}
+	}
+}
+
+func TestParseDiffContextLines(t *testing.T) {
+	diff := `--- a/goat.html
++++ b/goat.html
+@@ -1,7 +1,8 @@
+ <ul>
+ <li>1</li>
+ <li>2</li>
+ <li>3</li>
++<li>4</li>
+ <li>5</li>
+ <li>6</li>
+ </ul>
+@@ -20,3 +21,3 @@
+ <p>
+-<b>old</b>
+++++ goats
+ <p>
+\\ No newline at end of file
+--- a/kid.html
++++ b/kid.html
+@@ -1 +1 @@
+-<p>kid</p>
++<p>kids</p>
+`
+	changed, e := ParseDiff(strings.NewReader(diff))
+	if e != nil {
+		t.Fatal(e)
+	}
+	expected := ChangedLines{"goat.html": {{5, 5}, {22, 22}}, "kid.html": {{1, 1}}}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("received %v, expected %v", changed, expected)
+	}
+}
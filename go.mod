@@ -2,4 +2,10 @@ module github.com/noncombatant/html_lint
 
 go 1.22
 
-require golang.org/x/net v0.25.0
+require (
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.25.0
+	golang.org/x/sys v0.20.0 // indirect
+)
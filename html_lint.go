@@ -4,25 +4,430 @@
 package html_lint
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
 	"io"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	_ "golang.org/x/image/webp"
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 const (
 	timeFormat = "_2 January 2006"
 )
 
+// doubleSpace matches 2 or more spaces between words, but not indentation.
+var doubleSpace = regexp.MustCompile(`\S  +\S`)
+
+// tagLike matches text that looks like an HTML start or end tag.
+var tagLike = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*[\s/>]`)
+
+// URLSchemes are the URL schemes that LintURLSyntax recognizes.
+var URLSchemes = map[string]bool{
+	"about":      true,
+	"blob":       true,
+	"data":       true,
+	"file":       true,
+	"ftp":        true,
+	"geo":        true,
+	"http":       true,
+	"https":      true,
+	"irc":        true,
+	"javascript": true,
+	"magnet":     true,
+	"mailto":     true,
+	"sms":        true,
+	"tel":        true,
+	"urn":        true,
+	"webcal":     true,
+}
+
+// BooleanAttributes are the attributes whose presence alone turns them on, so
+// that their value must be empty or their own name.
+var BooleanAttributes = map[string]bool{
+	"allowfullscreen": true,
+	"async":           true,
+	"autofocus":       true,
+	"autoplay":        true,
+	"checked":         true,
+	"controls":        true,
+	"default":         true,
+	"defer":           true,
+	"disabled":        true,
+	"formnovalidate":  true,
+	"inert":           true,
+	"ismap":           true,
+	"itemscope":       true,
+	"loop":            true,
+	"multiple":        true,
+	"muted":           true,
+	"nomodule":        true,
+	"novalidate":      true,
+	"open":            true,
+	"playsinline":     true,
+	"readonly":        true,
+	"required":        true,
+	"reversed":        true,
+	"selected":        true,
+}
+
+// RobotsTokens are the directives that LintRobotsMeta recognizes in
+// <meta name="robots">. Directives that take a value, like max-snippet:50, are
+// listed by name.
+var RobotsTokens = map[string]bool{
+	"all":               true,
+	"follow":            true,
+	"index":             true,
+	"indexifembedded":   true,
+	"max-image-preview": true,
+	"max-snippet":       true,
+	"max-video-preview": true,
+	"noarchive":         true,
+	"nocache":           true,
+	"nofollow":          true,
+	"noimageindex":      true,
+	"noindex":           true,
+	"none":              true,
+	"nosnippet":         true,
+	"notranslate":       true,
+	"unavailable_after": true,
+}
+
+// rawTextElements are the elements whose content the tokenizer does not parse
+// as markup.
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+}
+
+// entity matches character references. They should not survive parsing, so
+// finding one in parsed text means that the source was escaped twice.
+var entity = regexp.MustCompile(`&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+// Severity is how serious a lint finding is. The zero value is Error, the most
+// serious.
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Info
+)
+
+var severityNames = []string{"error", "warning", "info"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, e := ParseSeverity(string(text))
+	if e != nil {
+		return e
+	}
+	*s = severity
+	return nil
+}
+
+// ParseSeverity returns the Severity named name.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return Severity(i), nil
+		}
+	}
+	return Error, fmt.Errorf("unknown severity: %q", name)
+}
+
+// Report collects the output of the linters. ErrorCount counts lint findings,
+// and FileErrorCount counts operational errors such as files that could not be
+// read or parsed, so that callers can tell the two apart. Counts counts lint
+// findings by Severity.
+//
+// Findings holds the lint findings that have been reported.
+//
+// Options configures the linters; if it is nil, DefaultOptions is used. If
+// Filter is not nil, findings for which it returns false are dropped without
+// being printed or counted.
 type Report struct {
 	io.Writer
-	ErrorCount int
+	ErrorCount     int
+	FileErrorCount int
+	Counts         map[Severity]int
+	Findings       []Finding
+	Options        *Options
+	Filter         func(Finding) bool
+
+	// rule is the Rule that is running, if any, and node is the node that it
+	// is linting.
+	rule *Rule
+	node *html.Node
+
+	// lines maps nodes to the lines of the source on which they begin.
+	lines map[*html.Node]int
+
+	// fragment is true while linting a fragment, rather than a complete
+	// document.
+	fragment bool
+}
+
+// A Finding is a single lint finding. Rule is empty for findings that do not
+// come from a Rule, and Line is 0 if the line is not known.
+type Finding struct {
+	Pathname string   `json:"pathname"`
+	Line     int      `json:"line,omitempty"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// CountAtLeast returns the number of lint findings that are at least as severe
+// as severity.
+func (r *Report) CountAtLeast(severity Severity) int {
+	count := 0
+	for s, n := range r.Counts {
+		if s <= severity {
+			count += n
+		}
+	}
+	return count
+}
+
+// Options holds the tunable parameters of the linters. It can be read from
+// JSON; see LoadOptions.
+type Options struct {
+	// MetaDescriptionMinLength and MetaDescriptionMaxLength bound the length
+	// of the content of <meta name="description">.
+	MetaDescriptionMinLength int `json:"meta-description-min-length"`
+	MetaDescriptionMaxLength int `json:"meta-description-max-length"`
+
+	// OpenGraphRequired and TwitterCardRequired list the properties that a
+	// document must declare if it declares any og:* or twitter:* properties,
+	// respectively.
+	OpenGraphRequired   []string `json:"open-graph-required"`
+	TwitterCardRequired []string `json:"twitter-card-required"`
+
+	// RequireAppleTouchIcon makes LintFavicon also require
+	// <link rel="apple-touch-icon">.
+	RequireAppleTouchIcon bool `json:"require-apple-touch-icon"`
+
+	// RequireMain makes LintMainLandmark also require a <main>, which not
+	// every fragment or template has.
+	RequireMain bool `json:"require-main"`
+
+	// AspectRatioTolerance is the relative difference between the aspect
+	// ratio of an <img>'s width and height attributes and that of the image
+	// file that LintAspectRatio allows.
+	AspectRatioTolerance float64 `json:"aspect-ratio-tolerance"`
+
+	// HTTPSHosts lists the hosts known to support HTTPS. LintInsecureLinks
+	// reports http: links to them.
+	HTTPSHosts []string `json:"https-hosts"`
+
+	// MaxCodeLineLength is the longest line, in characters, that
+	// LintCodeLineLength allows in <pre> and <code>.
+	MaxCodeLineLength int `json:"max-code-line-length"`
+
+	// Indentation is the indentation style, "spaces" or "tabs", that
+	// LintSourceIndentation requires.
+	Indentation string `json:"indentation"`
+
+	// MaxBlockingStylesheets is the number of render-blocking stylesheets
+	// that LintStylesheetLoading allows in <head>.
+	MaxBlockingStylesheets int `json:"max-blocking-stylesheets"`
+
+	// HeadingWordThreshold is the number of words of text that <body> may
+	// have before LintNoHeadings requires a heading.
+	HeadingWordThreshold int `json:"heading-word-threshold"`
+
+	// LineEndings is the line ending style, "lf" or "crlf", that
+	// LintLineEndings requires. If it is empty, any consistent style is
+	// allowed.
+	LineEndings string `json:"line-endings"`
+
+	// TableSectionRows is the number of rows that a <table> may have
+	// before LintTableSections requires <thead> or <tbody>.
+	TableSectionRows int `json:"table-section-rows"`
+
+	// MaxDepth is the deepest that Lint descends into a document. Browsers
+	// limit nesting, too, so deeper content is reported. If it is 0, there
+	// is no limit.
+	MaxDepth int `json:"max-depth"`
+
+	// CommentMarkers lists the words, such as TODO, that LintCommentMarkers
+	// reports in comments.
+	CommentMarkers []string `json:"comment-markers"`
+
+	// CommentedCodeMinLength is the length that a comment containing markup
+	// must exceed for LintCommentedCode to report it.
+	CommentedCodeMinLength int `json:"commented-code-min-length"`
+
+	// TimeLayouts lists the time.Parse layouts of the text of <time>
+	// elements that LintTimeNeedsDatetime accepts without a datetime
+	// attribute.
+	TimeLayouts []string `json:"time-layouts"`
+
+	// GenericLinkText lists the link texts, such as "click here", that
+	// LintLinkText reports as non-descriptive. Case, surrounding
+	// punctuation, and runs of whitespace are ignored.
+	GenericLinkText []string `json:"generic-link-text"`
+
+	// URLAttributes lists the attributes that LintURLSyntax checks.
+	URLAttributes []string `json:"url-attributes"`
+
+	// Enabled maps rule names to whether they are enabled, overriding the
+	// rules' defaults.
+	Enabled map[string]bool `json:"enabled"`
+
+	// Severity maps rule names to the severity, "error", "warning", or
+	// "info", of their findings, overriding the rules' defaults. "off"
+	// disables the rule, even if Enabled enables it.
+	Severity map[string]string `json:"severity"`
+}
+
+// SeverityOff is the Options.Severity that disables a rule.
+const SeverityOff = "off"
+
+// check returns an error if o names a rule that does not exist, or a severity
+// that does not exist.
+func (o *Options) check() error {
+	for name := range o.Enabled {
+		if e := CheckRuleName(name); e != nil {
+			return e
+		}
+	}
+	for name, severity := range o.Severity {
+		if e := CheckRuleName(name); e != nil {
+			return e
+		}
+		if severity == SeverityOff {
+			continue
+		}
+		if _, e := ParseSeverity(severity); e != nil {
+			return fmt.Errorf("rule %s: %w", name, e)
+		}
+	}
+	return nil
+}
+
+// DefaultOptions returns the options that the linters use unless told
+// otherwise.
+func DefaultOptions() *Options {
+	return &Options{
+		MetaDescriptionMinLength: 50,
+		MetaDescriptionMaxLength: 160,
+		OpenGraphRequired:        []string{"og:title", "og:type", "og:image", "og:url"},
+		TwitterCardRequired:      []string{"twitter:card", "twitter:title", "twitter:image"},
+		AspectRatioTolerance:     0.01,
+		MaxCodeLineLength:        80,
+		Indentation:              "spaces",
+		MaxBlockingStylesheets:   3,
+		HeadingWordThreshold:     200,
+		TableSectionRows:         10,
+		MaxDepth:                 512,
+		CommentMarkers:           []string{"TODO", "FIXME", "XXX"},
+		TimeLayouts:              []string{timeFormat},
+		GenericLinkText:          []string{"click here", "here", "read more", "link", "more"},
+		URLAttributes:            []string{"href", "src", "action", "cite"},
+	}
+}
+
+func (r *Report) options() *Options {
+	if r.Options == nil {
+		r.Options = DefaultOptions()
+	}
+	return r.Options
 }
 
+// Println reports a lint finding, with the Severity of the running Rule and
+// the line of the node that it is linting, if known. Findings that are less
+// severe than Error are labeled with their Severity.
+//
+// By convention, the first object is the pathname of the document, and the
+// rest are the message.
 func (r *Report) Println(objects ...interface{}) {
+	r.PrintlnAt(0, objects...)
+}
+
+// PrintlnAt is like Println, but reports the finding at line, rather than at
+// the line of the node being linted.
+func (r *Report) PrintlnAt(line int, objects ...interface{}) {
+	finding := Finding{Severity: Error, Line: line}
+	if r.rule != nil {
+		finding.Rule = r.rule.Name
+		finding.Severity = r.options().severity(*r.rule)
+	}
+	if finding.Line == 0 && r.node != nil {
+		finding.Line = r.lines[r.node]
+	}
+	message := objects
+	if len(objects) > 0 {
+		if pathname, ok := objects[0].(string); ok {
+			finding.Pathname = pathname
+			message = objects[1:]
+			if finding.Line > 0 {
+				objects = append([]interface{}{fmt.Sprintf("%s:%d", pathname, finding.Line)}, message...)
+			}
+		}
+	}
+	finding.Message = strings.TrimSuffix(fmt.Sprintln(message...), "\n")
+	if r.Filter != nil && !r.Filter(finding) {
+		return
+	}
+
+	severity := finding.Severity
+	r.Findings = append(r.Findings, finding)
 	r.ErrorCount += 1
+	if r.Counts == nil {
+		r.Counts = map[Severity]int{}
+	}
+	r.Counts[severity] += 1
+	if severity != Error {
+		objects = append([]interface{}{severity.String() + ":"}, objects...)
+	}
+	fmt.Fprintln(r.Writer, objects...)
+}
+
+// Warn prints a message that is neither a lint finding nor an operational
+// error, and so does not count toward either.
+func (r *Report) Warn(objects ...interface{}) {
+	fmt.Fprintln(r.Writer, objects...)
+}
+
+// FileError reports an operational error, such as a failure to open or parse
+// a file. It does not count as a lint finding.
+func (r *Report) FileError(objects ...interface{}) {
+	r.FileErrorCount += 1
 	fmt.Fprintln(r.Writer, objects...)
 }
 
@@ -38,6 +443,27 @@ func hasAttribute(as []html.Attribute, key, value string) bool {
 	return false
 }
 
+func getAttribute(as []html.Attribute, key string) (string, bool) {
+	for _, a := range as {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// hasToken returns true if the attribute key is a whitespace-separated list of
+// tokens that contains token, ignoring case (as for rel).
+func hasToken(as []html.Attribute, key, token string) bool {
+	value, _ := getAttribute(as, key)
+	for _, t := range strings.Fields(value) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
 func isElement(node *html.Node, tag string) bool {
 	return node.Type == html.ElementNode && node.Data == tag
 }
@@ -51,54 +477,114 @@ func hasParent(node *html.Node, tag string) bool {
 	return false
 }
 
-func hasChild(node *html.Node, tag string) bool {
-	if node == nil {
-		return false
-	}
-	for c := node.FirstChild; c != nil; c = c.NextSibling {
-		if c.Type == html.ElementNode && c.Data == tag {
-			return true
+// findNodes returns the descendants of node for which match returns true, in
+// document order.
+func findNodes(node *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	Walk(node, func(n *html.Node) {
+		if n != node && match(n) {
+			found = append(found, n)
 		}
-		if hasChild(c.FirstChild, tag) {
-			return true
+	})
+	return found
+}
+
+func findElements(node *html.Node, tag string) []*html.Node {
+	return findNodes(node, func(n *html.Node) bool { return isElement(n, tag) })
+}
+
+// textContent returns the concatenated text of node and its descendants.
+func textContent(node *html.Node) string {
+	var builder strings.Builder
+	Walk(node, func(n *html.Node) {
+		if n.Type == html.TextNode {
+			builder.WriteString(n.Data)
 		}
-	}
-	return false
+	})
+	return builder.String()
+}
+
+// isProse returns true if node is a text node that is not code, script, or
+// style. The parser treats the content of <noscript> as raw text, so that is
+// not prose either.
+func isProse(node *html.Node) bool {
+	return node.Type == html.TextNode && !hasParent(node, "pre") && !hasParent(node, "code") && !hasParent(node, "script") && !hasParent(node, "style") && !hasParent(node, "noscript")
+}
+
+// isInert returns true if node is inside a <template> or <noscript>, whose
+// content is not rendered as part of the page as is. Performance rules do not
+// apply to it.
+func isInert(node *html.Node) bool {
+	return hasParent(node, "template") || hasParent(node, "noscript")
+}
+
+// hasChild returns true if node has a descendant element named tag.
+func hasChild(node *html.Node, tag string) bool {
+	return node != nil && len(findElements(node, tag)) > 0
 }
 
 // LintLazyLoading ensures that <img> and <iframe> have loading=lazy and that
 // <script> has type=module. These attributes improve loading and rendering
 // performance; see
 // https://developer.mozilla.org/en-US/docs/Web/Performance/Lazy_loading.
+// Inert content in <template> and <noscript>, JSON-LD data, and import maps
+// are exempt.
 func LintLazyLoading(report *Report, node *html.Node, pathname string) {
 	if isElement(node, "img") || isElement(node, "iframe") {
-		if !hasAttribute(node.Attr, "loading", "lazy") {
+		if !hasAttribute(node.Attr, "loading", "lazy") && !isInert(node) {
 			report.Println(pathname, "<img>/<iframe> missing loading=lazy")
 		}
-	} else if isElement(node, "script") {
-		if !hasAttribute(node.Attr, "type", "module") {
+	} else if isElement(node, "script") && !isJSONLD(node) && !isScriptType(node, "importmap") {
+		if !hasAttribute(node.Attr, "type", "module") && !isInert(node) {
 			report.Println(pathname, "<script> missing type=module")
 		}
 	}
 }
 
-// LintWidthAndHeight ensures that <img> has width and height attributes. This
-// improves rendering performance by avoiding janky reflows.
+// LintWidthAndHeight ensures that <img>, <iframe>, <video>, and <embed> have
+// width and height attributes. This improves rendering performance by avoiding
+// janky reflows. It also ensures that the dimensions are non-negative
+// integers, as HTML requires (width="100px" is invalid).
 func LintWidthAndHeight(report *Report, node *html.Node, pathname string) {
-	if isElement(node, "img") {
-		if !hasAttribute(node.Attr, "width", "*") {
-			report.Println(pathname, "<img> missing width")
+	switch {
+	case isElement(node, "img"), isElement(node, "iframe"), isElement(node, "video"), isElement(node, "embed"):
+	default:
+		return
+	}
+	if isInert(node) {
+		return
+	}
+	tag := "<" + node.Data + ">"
+	for _, key := range []string{"width", "height"} {
+		value, ok := getAttribute(node.Attr, key)
+		if !ok || value == "" {
+			report.Println(pathname, tag, "missing", key)
+		} else if !isDimension(value) {
+			report.Println(pathname, tag, key, "is not a valid dimension")
 		}
-		if !hasAttribute(node.Attr, "height", "*") {
-			report.Println(pathname, "<img> missing height")
+	}
+}
+
+// isDimension returns true if value is a valid non-negative integer.
+func isDimension(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
 		}
 	}
+	return true
 }
 
-// LintAltText ensures that <img> has an alt attribute for accessibility.
+// LintAltText ensures that <img> and <input type=image> have an alt attribute
+// for accessibility.
 func LintAltText(report *Report, node *html.Node, pathname string) {
 	if isElement(node, "img") && !hasAttribute(node.Attr, "alt", "*") {
 		report.Println(pathname, "<img> missing alt")
+	} else if isElement(node, "input") && inputType(node) == "image" && !hasAttribute(node.Attr, "alt", "*") {
+		report.Println(pathname, "<input type=image> missing alt")
 	}
 }
 
@@ -110,6 +596,198 @@ func LintAName(report *Report, node *html.Node, pathname string) {
 	}
 }
 
+// LintIframeTitle ensures that <iframe> has a title attribute, which screen
+// readers use to announce it.
+func LintIframeTitle(report *Report, node *html.Node, pathname string) {
+	if isElement(node, "iframe") && !hasAttribute(node.Attr, "title", "*") {
+		report.Println(pathname, "<iframe> missing title")
+	}
+}
+
+// LintInsecureLinks ensures that <a href> and <form action> do not use http:
+// URLs to hosts that are listed in Options.HTTPSHosts.
+func LintInsecureLinks(report *Report, node *html.Node, pathname string) {
+	var value string
+	if isElement(node, "a") {
+		value, _ = getAttribute(node.Attr, "href")
+	} else if isElement(node, "form") {
+		value, _ = getAttribute(node.Attr, "action")
+	} else {
+		return
+	}
+	u, e := url.Parse(strings.TrimSpace(value))
+	if e != nil || !strings.EqualFold(u.Scheme, "http") {
+		return
+	}
+	for _, host := range report.options().HTTPSHosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			report.Println(pathname, "<"+node.Data+"> insecure link to host that supports HTTPS", value)
+			return
+		}
+	}
+}
+
+// LintURLSyntax ensures that the URL attributes listed in
+// Options.URLAttributes are valid URLs with recognized schemes.
+func LintURLSyntax(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode {
+		return
+	}
+	tag := "<" + node.Data + ">"
+	for _, key := range report.options().URLAttributes {
+		value, ok := getAttribute(node.Attr, key)
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.ContainsAny(value, " \t\n\r") && !strings.HasPrefix(value, "data:") {
+			report.Println(pathname, tag, key, "contains whitespace", value)
+			continue
+		}
+		u, e := url.Parse(value)
+		if e != nil {
+			report.Println(pathname, tag, key, "is not a valid URL:", e)
+			continue
+		}
+		if scheme := strings.ToLower(u.Scheme); scheme != "" && !URLSchemes[scheme] {
+			report.Println(pathname, tag, key, "has unrecognized scheme", u.Scheme)
+		}
+	}
+}
+
+// LintJavaScriptURL ensures that href does not use a javascript: URL, which
+// Content Security Policy blocks. A <button> with an event handler is better.
+func LintJavaScriptURL(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode {
+		return
+	}
+	href, ok := getAttribute(node.Attr, "href")
+	if !ok {
+		return
+	}
+	if u, e := url.Parse(strings.TrimSpace(href)); e == nil && strings.EqualFold(u.Scheme, "javascript") {
+		report.Println(pathname, "<"+node.Data+"> href uses javascript: URL")
+	}
+}
+
+// LintBooleanAttributes ensures that boolean attributes have an empty value
+// or their own name as their value. Any other value is misleading, because
+// (for example) disabled="false" still disables.
+func LintBooleanAttributes(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode {
+		return
+	}
+	for _, a := range node.Attr {
+		if BooleanAttributes[a.Key] && a.Val != "" && !strings.EqualFold(a.Val, a.Key) {
+			report.Println(pathname, fmt.Sprintf("<%s> boolean attribute %s=%q is on regardless of its value", node.Data, a.Key, a.Val))
+		}
+	}
+}
+
+// inputType returns the type of the <input> node, lowercased. An <input>
+// without a valid-looking type is a text input.
+func inputType(node *html.Node) string {
+	value, _ := getAttribute(node.Attr, "type")
+	if value = strings.ToLower(strings.TrimSpace(value)); value == "" {
+		return "text"
+	}
+	return value
+}
+
+// LintInputType ensures that <input> has an explicit type. The default, text,
+// is often not what was intended; types such as email and tel give better
+// mobile keyboards and validation.
+func LintInputType(report *Report, node *html.Node, pathname string) {
+	if isElement(node, "input") && !hasAttribute(node.Attr, "type", "*") {
+		report.Println(pathname, "<input> missing type")
+	}
+}
+
+// LintIconButtonName ensures that <button> and <a> whose only content is an
+// icon (<svg> or an icon font <i>) have an accessible name from aria-label,
+// aria-labelledby, or title.
+func LintIconButtonName(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "button") && !isElement(node, "a") {
+		return
+	}
+	if !hasChild(node, "svg") && !hasChild(node, "i") {
+		return
+	}
+	if strings.TrimSpace(textContent(node)) != "" || len(findNodes(node, func(n *html.Node) bool { return hasAttribute(n.Attr, "alt", "*") })) > 0 {
+		return
+	}
+	for _, key := range []string{"aria-label", "aria-labelledby", "title"} {
+		if hasAttribute(node.Attr, key, "*") {
+			return
+		}
+	}
+	report.Println(pathname, "<"+node.Data+"> with only an icon missing aria-label, aria-labelledby, or title")
+}
+
+// isFocusable returns true if the link is in the tab order.
+func isFocusable(node *html.Node) bool {
+	tabindex, _ := getAttribute(node.Attr, "tabindex")
+	return strings.TrimSpace(tabindex) != "-1" && !hasAttribute(node.Attr, "aria-hidden", "true")
+}
+
+// nextElementSibling returns the next sibling of node that is an element,
+// skipping whitespace-only text. It returns nil if there is none, or if there
+// is other content in between.
+func nextElementSibling(node *html.Node) *html.Node {
+	for s := node.NextSibling; s != nil; s = s.NextSibling {
+		switch s.Type {
+		case html.ElementNode:
+			return s
+		case html.TextNode:
+			if strings.TrimSpace(s.Data) != "" {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// LintDuplicateTabStops ensures that when 2 adjacent <a> elements link to the
+// same URL (typically an image link next to a text link), at most 1 of them is
+// in the tab order. Use tabindex="-1" on the other.
+func LintDuplicateTabStops(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "a") {
+		return
+	}
+	next := nextElementSibling(node)
+	if next == nil || !isElement(next, "a") {
+		return
+	}
+	href, ok := getAttribute(node.Attr, "href")
+	if nextHref, _ := getAttribute(next.Attr, "href"); !ok || href != nextHref {
+		return
+	}
+	if isFocusable(node) && isFocusable(next) {
+		report.Println(pathname, "adjacent <a> elements linking to", href, "are both in the tab order")
+	}
+}
+
+// LintBoldAsHeading ensures that no <p> consists only of a single <strong> or
+// <b>, which is usually a heading in disguise.
+func LintBoldAsHeading(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "p") {
+		return
+	}
+	var only *html.Node
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode && strings.TrimSpace(c.Data) == "" {
+			continue
+		}
+		if only != nil || !(isElement(c, "strong") || isElement(c, "b")) {
+			return
+		}
+		only = c
+	}
+	if only != nil && strings.TrimSpace(textContent(only)) != "" {
+		report.Println(pathname, "<p> contains only <"+only.Data+">; should it be a heading?")
+	}
+}
+
 // LintImgNestedInFigure ensures that <img> is nested inside a <figure> parent.
 func LintImgNestedInFigure(report *Report, node *html.Node, pathname string) {
 	if isElement(node, "img") && !hasParent(node, "figure") {
@@ -117,21 +795,128 @@ func LintImgNestedInFigure(report *Report, node *html.Node, pathname string) {
 	}
 }
 
-// LintTimeFormatting ensures that <time> elements are correctly formatted.
+// LintAspectRatio ensures that the width and height attributes of <img> have
+// the same aspect ratio as the image file that src refers to, so that the image
+// is not distorted. Only relative src URLs are checked, resolved relative to
+// pathname; images that cannot be read are skipped with a warning.
+func LintAspectRatio(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "img") || isInert(node) {
+		return
+	}
+	src, _ := getAttribute(node.Attr, "src")
+	width, _ := getAttribute(node.Attr, "width")
+	height, _ := getAttribute(node.Attr, "height")
+	u, e := url.Parse(src)
+	if e != nil || src == "" || u.Scheme != "" || u.Host != "" || strings.HasPrefix(u.Path, "/") || !isDimension(width) || !isDimension(height) {
+		return
+	}
+	w, _ := strconv.Atoi(width)
+	h, _ := strconv.Atoi(height)
+	if w == 0 || h == 0 {
+		return
+	}
+
+	imagePathname := filepath.Join(filepath.Dir(pathname), filepath.FromSlash(u.Path))
+	file, e := os.Open(imagePathname)
+	if e != nil {
+		report.Warn(pathname, "cannot check aspect ratio:", e)
+		return
+	}
+	defer file.Close()
+	config, _, e := image.DecodeConfig(file)
+	if e != nil {
+		report.Warn(pathname, "cannot check aspect ratio of", imagePathname+":", e)
+		return
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return
+	}
+
+	attributeRatio := float64(w) / float64(h)
+	imageRatio := float64(config.Width) / float64(config.Height)
+	if math.Abs(attributeRatio-imageRatio)/imageRatio > report.options().AspectRatioTolerance {
+		report.Println(pathname, fmt.Sprintf("<img> width and height (%dx%d) do not match the aspect ratio of %s (%dx%d)", w, h, src, config.Width, config.Height))
+	}
+}
+
+// LintTimeFormatting ensures that the first child of <time> elements is text.
 func LintTimeFormatting(report *Report, node *html.Node, pathname string) {
 	if isElement(node, "time") {
 		c := node.FirstChild
 		if c == nil || c.Type != html.TextNode {
 			report.Println(pathname, "<time> needs exactly 1 text child")
-		} else {
-			_, e := time.Parse(timeFormat, c.Data)
-			if e != nil {
-				report.Println(pathname, "<time> child", c.Data, "does not have correct format", timeFormat)
-			}
 		}
 	}
 }
 
+// LintTimeNeedsDatetime ensures that <time> elements whose text does not
+// match any of Options.TimeLayouts, such as "last Tuesday", have a
+// machine-readable datetime attribute, and that the datetime attribute, if
+// present, parses.
+func LintTimeNeedsDatetime(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "time") {
+		return
+	}
+	if value, ok := getAttribute(node.Attr, "datetime"); ok {
+		if _, _, ok := parseTime(strings.TrimSpace(value), datetimeLayouts); !ok {
+			report.Println(pathname, "<time> datetime", value, "is not a valid date or time")
+		}
+		return
+	}
+	c := node.FirstChild
+	if c == nil || c.Type != html.TextNode {
+		return
+	}
+	layouts := report.options().TimeLayouts
+	for _, layout := range layouts {
+		if _, e := time.Parse(layout, c.Data); e == nil {
+			return
+		}
+	}
+	report.Println(pathname, "<time> child", c.Data, "does not have correct format", strings.Join(layouts, " or "), "and needs a datetime attribute")
+}
+
+// datetimeLayouts are the time.Parse layouts of common datetime attribute
+// values.
+var datetimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// parseTime parses value with the first of layouts that fits, and returns the
+// time and the layout.
+func parseTime(value string, layouts []string) (time.Time, string, bool) {
+	for _, layout := range layouts {
+		if t, e := time.Parse(layout, value); e == nil {
+			return t, layout, true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// LintTimeConsistency ensures that when both the text and the datetime
+// attribute of a <time> parse, they agree, to the precision of the text.
+func LintTimeConsistency(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "time") || node.FirstChild == nil || node.FirstChild.Type != html.TextNode {
+		return
+	}
+	value, _ := getAttribute(node.Attr, "datetime")
+	datetime, _, ok := parseTime(strings.TrimSpace(value), datetimeLayouts)
+	if !ok {
+		return
+	}
+	text := strings.TrimSpace(node.FirstChild.Data)
+	parsed, layout, ok := parseTime(text, append(append([]string{}, report.options().TimeLayouts...), datetimeLayouts...))
+	if ok && parsed.Format(layout) != datetime.Format(layout) {
+		report.Println(pathname, "<time> text", text, "does not match datetime", value)
+	}
+}
+
 // LintFigureHasFigcaption ensures that <figure> has a <figcaption> child.
 func LintFigureHasFigcaption(report *Report, node *html.Node, pathname string) {
 	if isElement(node, "figure") && !hasChild(node, "figcaption") {
@@ -140,11 +925,17 @@ func LintFigureHasFigcaption(report *Report, node *html.Node, pathname string) {
 }
 
 // LintCurlyQuotes ensures that non-code text nodes, alt attributes, and title
-// attributes use curly quotes.
+// attributes use curly quotes. A text node that mixes straight and curly quotes
+// is almost certainly a passage that was only partly converted, so it gets its
+// own finding.
 func LintCurlyQuotes(report *Report, node *html.Node, pathname string) {
-	if node.Type == html.TextNode && !hasParent(node, "pre") && !hasParent(node, "code") && !hasParent(node, "script") && !hasParent(node, "style") {
+	if isProse(node) {
 		if strings.ContainsAny(node.Data, "'\"") {
-			report.Println(pathname, "contains non-curly quotes text node", node.Data)
+			if strings.ContainsAny(node.Data, "‘’“”") {
+				report.Println(pathname, "mixes straight and curly quotes in text node", node.Data)
+			} else {
+				report.Println(pathname, "contains non-curly quotes text node", node.Data)
+			}
 		}
 	}
 	if isElement(node, "img") {
@@ -158,50 +949,2317 @@ func LintCurlyQuotes(report *Report, node *html.Node, pathname string) {
 	}
 }
 
-// Lint applies all the Lint* functions and then recurses down the tree.
-func Lint(report *Report, node *html.Node, pathname string) {
-	LintLazyLoading(report, node, pathname)
-	LintWidthAndHeight(report, node, pathname)
-	LintAltText(report, node, pathname)
-	LintAName(report, node, pathname)
-	LintImgNestedInFigure(report, node, pathname)
-	LintTimeFormatting(report, node, pathname)
-	LintFigureHasFigcaption(report, node, pathname)
-	LintCurlyQuotes(report, node, pathname)
+// LintDashes ensures that prose text nodes use em dashes (—) rather than
+// double hyphens (--).
+func LintDashes(report *Report, node *html.Node, pathname string) {
+	if isProse(node) && strings.Contains(node.Data, "--") {
+		report.Println(pathname, "contains -- instead of em dash in text node", node.Data)
+	}
+}
 
-	for c := node.FirstChild; c != nil; c = c.NextSibling {
-		Lint(report, c, pathname)
+// LintEllipsis ensures that prose text nodes use the ellipsis character (…)
+// rather than three periods.
+func LintEllipsis(report *Report, node *html.Node, pathname string) {
+	if isProse(node) && strings.Contains(node.Data, "...") {
+		report.Println(pathname, "contains ... instead of ellipsis in text node", node.Data)
 	}
 }
 
-// LintNesting ensures that all tags are properly closed.
-func LintNesting(report *Report, reader io.Reader, pathname string) {
-	z := html.NewTokenizer(reader)
-	var stack []string
+// LintDoubleSpaces ensures that prose text nodes do not separate words with
+// more than 1 space.
+func LintDoubleSpaces(report *Report, node *html.Node, pathname string) {
+	if isProse(node) && doubleSpace.MatchString(node.Data) {
+		report.Println(pathname, "contains multiple spaces in text node", node.Data)
+	}
+}
 
-	for {
-		token := z.Next()
-		if token == html.ErrorToken {
+// spaceBeforePunctuation matches text that begins with whitespace and then
+// punctuation, such as the text after <a>link</a> in "<a>link</a> .".
+var spaceBeforePunctuation = regexp.MustCompile(`^\s+[.,;:!?)]`)
+
+// LintTextWhitespace ensures that prose text nodes do not contain tabs or
+// unusual space characters, which look like ordinary or non-breaking spaces
+// but behave differently, and that text after an element does not begin with
+// a space before punctuation.
+func LintTextWhitespace(report *Report, node *html.Node, pathname string) {
+	if !isProse(node) {
+		return
+	}
+	if strings.ContainsRune(node.Data, '\t') {
+		report.Println(pathname, "contains tab in text node", strconv.Quote(node.Data))
+	}
+	for _, r := range node.Data {
+		if unicode.Is(unicode.Zs, r) && r != ' ' && r != '\u00a0' {
+			report.Println(pathname, fmt.Sprintf("contains %U space in text node", r), strconv.Quote(node.Data))
 			break
 		}
-		tagBytes, _ := z.TagName()
-		tag := string(tagBytes)
-		if token == html.StartTagToken {
-			stack = append(stack, tag)
-		} else if token == html.EndTagToken {
-			if len(stack) == 0 {
-				report.Println(pathname, "tag stack underflow")
-			}
-			last := len(stack) - 1
-			previous := stack[last]
-			if tag != previous {
-				report.Println(pathname, "Unmatched pair", string(tag), string(previous))
-			}
-			stack = stack[:last]
+	}
+	if node.PrevSibling != nil && node.PrevSibling.Type == html.ElementNode && spaceBeforePunctuation.MatchString(node.Data) {
+		report.Println(pathname, "space before punctuation after <"+node.PrevSibling.Data+">")
+	}
+}
+
+// LintDoubleEscape ensures that prose text nodes are not double-escaped (such
+// as &amp;amp; in the source, which renders as &amp;).
+func LintDoubleEscape(report *Report, node *html.Node, pathname string) {
+	if isProse(node) {
+		if m := entity.FindString(node.Data); m != "" {
+			report.Println(pathname, "contains double-escaped entity", m, "in text node", node.Data)
 		}
 	}
+}
 
-	if len(stack) != 0 {
+// LintCodeLineLength ensures that lines of text in <pre> and <code> are not so
+// long that readers have to scroll horizontally.
+func LintCodeLineLength(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.TextNode || !(hasParent(node, "pre") || hasParent(node, "code")) {
+		return
+	}
+	maximum := report.options().MaxCodeLineLength
+	for _, line := range strings.Split(node.Data, "\n") {
+		if length := utf8.RuneCountInString(line); length > maximum {
+			report.Println(pathname, "code line of", length, "characters is longer than", maximum, line)
+		}
+	}
+}
+
+// LintCommentedCode ensures that comments longer than
+// Options.CommentedCodeMinLength do not contain markup, which is usually
+// commented-out code that should be removed.
+func LintCommentedCode(report *Report, node *html.Node, pathname string) {
+	if node.Type == html.CommentNode && len(node.Data) > report.options().CommentedCodeMinLength && tagLike.MatchString(node.Data) {
+		report.Println(pathname, "comment contains commented-out markup", strings.TrimSpace(node.Data))
+	}
+}
+
+// isWordByte returns true if b is a word character, as in the \w of regexp.
+func isWordByte(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// isWordBoundary returns true if there is a word boundary, as in the \b of
+// regexp, at index i of text.
+func isWordBoundary(text string, i int) bool {
+	before := i > 0 && isWordByte(text[i-1])
+	after := i < len(text) && isWordByte(text[i])
+	return before != after
+}
+
+// findWord returns the index of the first occurrence of word in text that is
+// a whole word, or -1 if there is none.
+func findWord(text, word string) int {
+	for offset := 0; word != "" && offset <= len(text); {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			break
+		}
+		i += offset
+		if isWordBoundary(text, i) && isWordBoundary(text, i+len(word)) {
+			return i
+		}
+		offset = i + 1
+	}
+	return -1
+}
+
+// LintCommentMarkers ensures that comments do not contain the words in
+// Options.CommentMarkers, such as TODO and FIXME, which are usually leftovers
+// that should not ship.
+func LintCommentMarkers(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.CommentNode {
+		return
+	}
+	first, marker := -1, ""
+	for _, m := range report.options().CommentMarkers {
+		if i := findWord(node.Data, m); i >= 0 && (first < 0 || i < first) {
+			first, marker = i, m
+		}
+	}
+	if first >= 0 {
+		report.Println(pathname, "comment contains", marker+":", strings.TrimSpace(node.Data))
+	}
+}
+
+// LintConditionalComments ensures that there are no Internet Explorer
+// conditional comments (<!--[if IE]>), which are obsolete.
+func LintConditionalComments(report *Report, node *html.Node, pathname string) {
+	if node.Type == html.CommentNode && strings.Contains(node.Data, "[if ") {
+		report.Println(pathname, "comment contains obsolete conditional comment syntax")
+	}
+}
+
+// LintLegacyIE ensures that there are no <meta http-equiv="X-UA-Compatible">
+// tags or stray <![endif]> markers, which only Internet Explorer used. (See
+// LintConditionalComments for the conditional comments themselves.)
+func LintLegacyIE(report *Report, node *html.Node, pathname string) {
+	if isElement(node, "meta") {
+		if value, _ := getAttribute(node.Attr, "http-equiv"); strings.EqualFold(strings.TrimSpace(value), "X-UA-Compatible") {
+			report.Println(pathname, "<meta http-equiv=X-UA-Compatible> is obsolete")
+		}
+	} else if node.Type == html.CommentNode && strings.TrimSpace(node.Data) == "[endif]" {
+		report.Println(pathname, "comment contains obsolete <![endif]>")
+	}
+}
+
+// LintMetaDescription ensures that <head> has a <meta name="description">,
+// and that its content is neither too short nor too long to be useful in
+// search results.
+func LintMetaDescription(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "head") {
+		return
+	}
+	for _, meta := range findElements(node, "meta") {
+		if !hasAttribute(meta.Attr, "name", "description") {
+			continue
+		}
+		content, _ := getAttribute(meta.Attr, "content")
+		length := utf8.RuneCountInString(strings.TrimSpace(content))
+		options := report.options()
+		if length < options.MetaDescriptionMinLength || length > options.MetaDescriptionMaxLength {
+			report.Println(pathname, "<meta name=description> content length", length, "not in range", options.MetaDescriptionMinLength, "to", options.MetaDescriptionMaxLength)
+		}
+		return
+	}
+	report.Println(pathname, "<head> missing <meta name=description>")
+}
+
+// LintOpenGraph ensures that a document that declares any Open Graph (og:*)
+// or Twitter Card (twitter:*) metadata declares the complete required set, so
+// that link previews work.
+func LintOpenGraph(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "head") {
+		return
+	}
+	present := map[string]bool{}
+	for _, meta := range findElements(node, "meta") {
+		for _, key := range []string{"property", "name"} {
+			if value, ok := getAttribute(meta.Attr, key); ok {
+				present[value] = true
+			}
+		}
+	}
+	options := report.options()
+	lintMetaSet(report, pathname, present, "og:", options.OpenGraphRequired)
+	lintMetaSet(report, pathname, present, "twitter:", options.TwitterCardRequired)
+}
+
+func lintMetaSet(report *Report, pathname string, present map[string]bool, prefix string, required []string) {
+	declared := false
+	for p := range present {
+		if strings.HasPrefix(p, prefix) {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		return
+	}
+	var missing []string
+	for _, r := range required {
+		if !present[r] {
+			missing = append(missing, r)
+		}
+	}
+	if len(missing) > 0 {
+		report.Println(pathname, "<head> has "+prefix+"* metadata but is missing", strings.Join(missing, ", "))
+	}
+}
+
+// LintCanonical ensures that <head> has at most one <link rel="canonical">,
+// and that its href is an absolute URL.
+func LintCanonical(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "head") {
+		return
+	}
+	var canonicals []*html.Node
+	for _, link := range findElements(node, "link") {
+		if hasToken(link.Attr, "rel", "canonical") {
+			canonicals = append(canonicals, link)
+		}
+	}
+	if len(canonicals) > 1 {
+		report.Println(pathname, "<head> has", len(canonicals), "<link rel=canonical>; should have 1")
+	}
+	for _, link := range canonicals {
+		href, _ := getAttribute(link.Attr, "href")
+		if u, e := url.Parse(href); e != nil || !u.IsAbs() || u.Host == "" {
+			report.Println(pathname, "<link rel=canonical> href", href, "is not an absolute URL")
+		}
+	}
+}
+
+// LintFavicon ensures that <head> declares an icon with <link rel="icon"> (or
+// rel="shortcut icon"), and optionally an apple-touch-icon.
+func LintFavicon(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "head") {
+		return
+	}
+	icon, appleTouchIcon := false, false
+	for _, link := range findElements(node, "link") {
+		icon = icon || hasToken(link.Attr, "rel", "icon")
+		appleTouchIcon = appleTouchIcon || hasToken(link.Attr, "rel", "apple-touch-icon")
+	}
+	if !icon {
+		report.Println(pathname, "<head> missing <link rel=icon>")
+	}
+	if !appleTouchIcon && report.options().RequireAppleTouchIcon {
+		report.Println(pathname, "<head> missing <link rel=apple-touch-icon>")
+	}
+}
+
+// LintStylesheetLoading ensures that <head> does not have too many
+// render-blocking stylesheets: <link rel="stylesheet"> with no media query, or
+// one that matches all media.
+func LintStylesheetLoading(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "head") {
+		return
+	}
+	blocking := 0
+	for _, link := range findElements(node, "link") {
+		media, _ := getAttribute(link.Attr, "media")
+		media = strings.ToLower(strings.TrimSpace(media))
+		if hasToken(link.Attr, "rel", "stylesheet") && (media == "" || media == "all" || media == "screen") {
+			blocking += 1
+		}
+	}
+	if maximum := report.options().MaxBlockingStylesheets; blocking > maximum {
+		report.Println(pathname, fmt.Sprintf("<head> has %d render-blocking stylesheets, more than %d; consider a media query or preload for non-critical CSS", blocking, maximum))
+	}
+}
+
+// origin returns the scheme and host of an absolute http: or https: URL, or ""
+// if it is not one. Protocol-relative URLs get the https: scheme.
+func origin(value string) string {
+	u, e := url.Parse(strings.TrimSpace(value))
+	if e != nil || u.Host == "" {
+		return ""
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "" {
+		scheme = "https"
+	} else if scheme != "http" && scheme != "https" {
+		return ""
+	}
+	return scheme + "://" + strings.ToLower(u.Host)
+}
+
+// LintPreconnect ensures that third-party origins that the document loads
+// resources from more than once have a <link rel="preconnect"> or
+// rel="dns-prefetch". The document's own origin is inferred from
+// <link rel="canonical">, if any.
+func LintPreconnect(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	self := ""
+	hinted := map[string]bool{}
+	counts := map[string]int{}
+	var origins []string
+	for _, n := range findNodes(node, func(n *html.Node) bool { return n.Type == html.ElementNode }) {
+		var value string
+		switch {
+		case isElement(n, "link") && hasToken(n.Attr, "rel", "canonical"):
+			href, _ := getAttribute(n.Attr, "href")
+			self = origin(href)
+			continue
+		case isElement(n, "link") && (hasToken(n.Attr, "rel", "preconnect") || hasToken(n.Attr, "rel", "dns-prefetch")):
+			href, _ := getAttribute(n.Attr, "href")
+			hinted[origin(href)] = true
+			hinted[strings.Replace(origin(href), "http://", "https://", 1)] = true
+			continue
+		case isElement(n, "script") || isElement(n, "img"):
+			value, _ = getAttribute(n.Attr, "src")
+		case isElement(n, "link"):
+			value, _ = getAttribute(n.Attr, "href")
+		default:
+			continue
+		}
+		if o := origin(value); o != "" {
+			if counts[o] == 0 {
+				origins = append(origins, o)
+			}
+			counts[o] += 1
+		}
+	}
+	for _, o := range origins {
+		if o != self && counts[o] > 1 && !hinted[o] {
+			report.Println(pathname, "origin", o, "used", counts[o], "times without preconnect")
+		}
+	}
+}
+
+// LintAuthorMeta ensures that the document identifies its author with
+// <meta name="author"> or a rel="author" link.
+func LintAuthorMeta(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	authors := findNodes(node, func(n *html.Node) bool {
+		return (isElement(n, "meta") && hasAttribute(n.Attr, "name", "author")) || ((isElement(n, "link") || isElement(n, "a")) && hasToken(n.Attr, "rel", "author"))
+	})
+	if len(authors) == 0 {
+		report.Println(pathname, "document missing <meta name=author> or rel=author link")
+	}
+}
+
+// LintRobotsCanonicalConflict ensures that a document with
+// <meta name="robots" content="noindex"> does not also have a
+// <link rel="canonical"> to a different URL, which asks search engines to
+// index that URL instead: the 2 signals conflict. A canonical link to the
+// document itself is fine.
+func LintRobotsCanonicalConflict(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "head") {
+		return
+	}
+	noindex := false
+	canonical, own := "", ""
+	for _, n := range findNodes(node, func(n *html.Node) bool { return n.Type == html.ElementNode }) {
+		if isElement(n, "meta") && hasAttribute(n.Attr, "name", "robots") {
+			content, _ := getAttribute(n.Attr, "content")
+			for _, token := range strings.Split(content, ",") {
+				noindex = noindex || strings.EqualFold(strings.TrimSpace(token), "noindex")
+			}
+		} else if isElement(n, "meta") && hasAttribute(n.Attr, "property", "og:url") {
+			own, _ = getAttribute(n.Attr, "content")
+		} else if isElement(n, "link") && hasToken(n.Attr, "rel", "canonical") {
+			canonical, _ = getAttribute(n.Attr, "href")
+		}
+	}
+	if noindex && !isSelfURL(canonical, pathname, own) {
+		report.Println(pathname, "<meta name=robots> has noindex but <link rel=canonical> points to", canonical)
+	}
+}
+
+// isSelfURL returns true if href refers to the document at pathname, whose
+// own URL, if known, is own. An empty href, or one that is only a fragment,
+// refers to the document. An absolute href refers to it only if it has the
+// same host and path as own. The path of a relative href must match the end
+// of pathname, taking index.html and extensionless URLs into account.
+func isSelfURL(href, pathname, own string) bool {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return true
+	}
+	u, e := url.Parse(href)
+	if e != nil {
+		return false
+	}
+	if u.IsAbs() || u.Host != "" {
+		o, e := url.Parse(strings.TrimSpace(own))
+		if e != nil || own == "" || u.Host == "" || !strings.EqualFold(u.Host, o.Host) {
+			return false
+		}
+		return strings.TrimSuffix(u.Path, "/") == strings.TrimSuffix(o.Path, "/")
+	}
+	if pathname == "" {
+		return false
+	}
+	file := filepath.ToSlash(filepath.Clean(pathname))
+	p := u.Path
+	if !strings.HasPrefix(p, "/") {
+		p = path.Join(path.Dir(file), p)
+		if strings.HasSuffix(u.Path, "/") || u.Path == "" {
+			p += "/"
+		}
+	}
+	p = strings.TrimPrefix(p, "/")
+	candidates := []string{p}
+	if p == "" || strings.HasSuffix(p, "/") {
+		candidates = []string{p + "index.html", p + "index.htm"}
+	} else if path.Ext(p) == "" {
+		candidates = []string{p + ".html", p + "/index.html"}
+	}
+	for _, c := range candidates {
+		if file == c || strings.HasSuffix(file, "/"+c) {
+			return true
+		}
+	}
+	return false
+}
+
+// primaryLanguage returns the primary language subtag of a language tag such as
+// en-US or a locale such as en_US.
+func primaryLanguage(tag string) string {
+	subtags := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' || unicode.IsSpace(r) })
+	if len(subtags) == 0 {
+		return ""
+	}
+	return strings.ToLower(subtags[0])
+}
+
+// LintConflictingMeta ensures that the document does not declare 2 different
+// character encodings, more than 1 viewport, or an og:locale in a different
+// language than <html lang>. These typically happen when 2 templates each add
+// the same metadata.
+func LintConflictingMeta(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	charset := ""
+	viewports := 0
+	locale := ""
+	for _, meta := range findElements(node, "meta") {
+		value, ok := getAttribute(meta.Attr, "charset")
+		if equiv, _ := getAttribute(meta.Attr, "http-equiv"); !ok && strings.EqualFold(equiv, "Content-Type") {
+			content, _ := getAttribute(meta.Attr, "content")
+			if _, after, found := strings.Cut(strings.ToLower(content), "charset="); found {
+				value, ok = strings.TrimSpace(after), true
+			}
+		}
+		if ok {
+			if charset != "" && !strings.EqualFold(charset, value) {
+				report.Println(pathname, "<meta> charset", value, "conflicts with charset", charset)
+			} else if charset == "" {
+				charset = value
+			}
+		}
+		if hasAttribute(meta.Attr, "name", "viewport") {
+			viewports += 1
+		}
+		if hasAttribute(meta.Attr, "property", "og:locale") {
+			locale, _ = getAttribute(meta.Attr, "content")
+		}
+	}
+	if viewports > 1 {
+		report.Println(pathname, "document has", viewports, "<meta name=viewport>; should have 1")
+	}
+	for _, h := range findElements(node, "html") {
+		lang, _ := getAttribute(h.Attr, "lang")
+		if strings.TrimSpace(lang) != "" && strings.TrimSpace(locale) != "" && primaryLanguage(lang) != primaryLanguage(locale) {
+			report.Println(pathname, "<html lang="+lang+"> conflicts with og:locale", locale)
+		}
+	}
+}
+
+// LintRobotsMeta ensures that <meta name="robots"> contains only directives in
+// RobotsTokens. Search engines silently ignore misspelled directives.
+func LintRobotsMeta(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "meta") || !hasAttribute(node.Attr, "name", "robots") {
+		return
+	}
+	content, _ := getAttribute(node.Attr, "content")
+	for _, token := range strings.Split(content, ",") {
+		token = strings.TrimSpace(token)
+		name, _, _ := strings.Cut(token, ":")
+		if token != "" && !RobotsTokens[strings.ToLower(strings.TrimSpace(name))] {
+			report.Println(pathname, "<meta name=robots> has unknown directive", token)
+		}
+	}
+}
+
+// isHeading returns true if node is a heading element, or has role=heading.
+func isHeading(node *html.Node) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+	switch node.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return hasAttribute(node.Attr, "role", "heading")
+}
+
+// LintNoHeadings ensures that a <body> with more than
+// Options.HeadingWordThreshold words of text has at least 1 heading, which
+// screen reader users navigate by.
+func LintNoHeadings(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "body") || len(findNodes(node, isHeading)) > 0 {
+		return
+	}
+	words := 0
+	for _, text := range findNodes(node, isProse) {
+		words += len(strings.Fields(text.Data))
+	}
+	if threshold := report.options().HeadingWordThreshold; words > threshold {
+		report.Println(pathname, "<body> has", words, "words but no headings")
+	}
+}
+
+// LintAutofocus ensures that at most 1 element in the document has autofocus.
+func LintAutofocus(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	focused := findNodes(node, func(n *html.Node) bool {
+		_, ok := getAttribute(n.Attr, "autofocus")
+		return n.Type == html.ElementNode && ok
+	})
+	if len(focused) > 1 {
+		var tags []string
+		for _, n := range focused {
+			tags = append(tags, "<"+n.Data+">")
+		}
+		report.Println(pathname, len(focused), "elements have autofocus; should have at most 1:", strings.Join(tags, ", "))
+	}
+}
+
+// isJSONLD returns true if node is a <script type="application/ld+json">.
+func isJSONLD(node *html.Node) bool {
+	if !isElement(node, "script") {
+		return false
+	}
+	value, _ := getAttribute(node.Attr, "type")
+	return strings.EqualFold(strings.TrimSpace(value), "application/ld+json")
+}
+
+// scriptJSON parses the text of a <script> that contains JSON, such as JSON-LD
+// or an import map.
+func scriptJSON(node *html.Node) (interface{}, error) {
+	var value interface{}
+	e := json.Unmarshal([]byte(textContent(node)), &value)
+	return value, e
+}
+
+// LintJSONLD ensures that <script type="application/ld+json"> contains valid
+// JSON. Search engines silently ignore structured data that does not parse.
+func LintJSONLD(report *Report, node *html.Node, pathname string) {
+	if !isJSONLD(node) {
+		return
+	}
+	if _, e := scriptJSON(node); e != nil {
+		report.Println(pathname, "invalid JSON-LD:", e)
+	}
+}
+
+// LintJSONLDContext ensures that the top-level objects of JSON-LD structured
+// data have an @context of https://schema.org, which search engines expect.
+func LintJSONLDContext(report *Report, node *html.Node, pathname string) {
+	if !isJSONLD(node) {
+		return
+	}
+	value, e := scriptJSON(node)
+	if e != nil {
+		return
+	}
+	objects, ok := value.([]interface{})
+	if !ok {
+		objects = []interface{}{value}
+	}
+	for _, o := range objects {
+		object, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		context, _ := object["@context"].(string)
+		if strings.TrimSuffix(context, "/") != "https://schema.org" {
+			report.Println(pathname, "JSON-LD @context is not https://schema.org")
+		}
+	}
+}
+
+// LintDuplicateListItems ensures that adjacent <li> siblings do not have the
+// same text, which is usually a copy-and-paste mistake.
+func LintDuplicateListItems(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "li") {
+		return
+	}
+	next := nextElementSibling(node)
+	if next == nil || !isElement(next, "li") {
+		return
+	}
+	text := strings.Join(strings.Fields(textContent(node)), " ")
+	if text != "" && text == strings.Join(strings.Fields(textContent(next)), " ") {
+		report.Println(pathname, "adjacent <li> elements have the same text:", text)
+	}
+}
+
+// LintEmptyList ensures that <ul> and <ol> have at least 1 <li>. An empty list
+// renders nothing, and is likely a mistake.
+func LintEmptyList(report *Report, node *html.Node, pathname string) {
+	if (isElement(node, "ul") || isElement(node, "ol")) && !hasChild(node, "li") {
+		report.Println(pathname, "<"+node.Data+"> has no <li>")
+	}
+}
+
+// CSSAtRules are the CSS at-rules that LintInlineCSS recognizes. Vendor-prefixed
+// at-rules, such as @-webkit-keyframes, are always allowed.
+var CSSAtRules = map[string]bool{
+	"charset":             true,
+	"color-profile":       true,
+	"container":           true,
+	"counter-style":       true,
+	"document":            true,
+	"font-face":           true,
+	"font-feature-values": true,
+	"font-palette-values": true,
+	"import":              true,
+	"keyframes":           true,
+	"layer":               true,
+	"media":               true,
+	"namespace":           true,
+	"page":                true,
+	"position-try":        true,
+	"property":            true,
+	"scope":               true,
+	"starting-style":      true,
+	"supports":            true,
+	"view-transition":     true,
+}
+
+// LintInlineCSS checks <style> for gross syntax errors: unbalanced braces,
+// unterminated comments and strings, text after the last rule, and unknown
+// at-rules. It is not a CSS parser, and errs on the side of silence.
+func LintInlineCSS(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "style") {
+		return
+	}
+	css := textContent(node)
+	depth := 0
+	statement := strings.Builder{}
+	for i := 0; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				report.Println(pathname, "<style> has unterminated comment")
+				return
+			}
+			i += end + 3
+		case c == '"' || c == '\'':
+			end := i + 1
+			for ; end < len(css) && css[end] != c && css[end] != '\n'; end++ {
+				if css[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(css) || css[end] != c {
+				report.Println(pathname, "<style> has unterminated string")
+				return
+			}
+			statement.WriteString(css[i : end+1])
+			i = end
+		case c == '@' && depth == 0 && strings.TrimSpace(statement.String()) == "":
+			end := i + 1
+			for end < len(css) && (css[end] == '-' || css[end] == '_' || unicode.IsLetter(rune(css[end])) || unicode.IsDigit(rune(css[end]))) {
+				end++
+			}
+			name := strings.ToLower(css[i+1 : end])
+			if !CSSAtRules[name] && !strings.HasPrefix(name, "-") {
+				report.Println(pathname, "<style> has unknown at-rule @"+name)
+			}
+			statement.WriteString(css[i:end])
+			i = end - 1
+		case c == '{':
+			depth++
+			statement.Reset()
+		case c == '}':
+			if depth == 0 {
+				report.Println(pathname, "<style> has unbalanced }")
+				return
+			}
+			depth--
+			statement.Reset()
+		case c == ';':
+			statement.Reset()
+		default:
+			statement.WriteByte(c)
+		}
+	}
+	if depth > 0 {
+		report.Println(pathname, "<style> has unclosed {")
+	} else if text := strings.TrimSpace(statement.String()); text != "" {
+		report.Println(pathname, "<style> ends with dangling text", strconv.Quote(text))
+	}
+}
+
+// LintFileUploadForm ensures that a <form> containing <input type=file> has
+// method=post and enctype=multipart/form-data. Otherwise, the browser sends
+// only the file names, and the upload silently fails.
+func LintFileUploadForm(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "form") {
+		return
+	}
+	files := findNodes(node, func(n *html.Node) bool {
+		value, _ := getAttribute(n.Attr, "type")
+		return isElement(n, "input") && strings.EqualFold(strings.TrimSpace(value), "file")
+	})
+	if len(files) == 0 {
+		return
+	}
+	if method, _ := getAttribute(node.Attr, "method"); !strings.EqualFold(strings.TrimSpace(method), "post") {
+		report.Println(pathname, "<form> with <input type=file> missing method=post")
+	}
+	if enctype, _ := getAttribute(node.Attr, "enctype"); !strings.EqualFold(strings.TrimSpace(enctype), "multipart/form-data") {
+		report.Println(pathname, "<form> with <input type=file> missing enctype=multipart/form-data")
+	}
+}
+
+// ariaLevelRoles are the roles that support aria-level.
+var ariaLevelRoles = []string{"heading", "treeitem", "row", "listitem", "comment"}
+
+// LintAriaLevel ensures that aria-level is a positive integer on an element
+// with a role, such as heading, that supports it. Elsewhere, it has no effect.
+func LintAriaLevel(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode {
+		return
+	}
+	level, ok := getAttribute(node.Attr, "aria-level")
+	if !ok {
+		return
+	}
+	if n, e := strconv.Atoi(strings.TrimSpace(level)); e != nil || n < 1 {
+		report.Println(pathname, "<"+node.Data+"> aria-level", strconv.Quote(level), "is not a positive integer")
+	}
+	for _, role := range ariaLevelRoles {
+		if hasToken(node.Attr, "role", role) {
+			return
+		}
+	}
+	report.Println(pathname, "<"+node.Data+"> has aria-level without role=heading")
+}
+
+// interactiveElements are the elements that are interactive on their own.
+var interactiveElements = map[string]bool{
+	"a":        true,
+	"audio":    true,
+	"button":   true,
+	"details":  true,
+	"embed":    true,
+	"iframe":   true,
+	"input":    true,
+	"select":   true,
+	"summary":  true,
+	"textarea": true,
+	"video":    true,
+}
+
+// interactiveRoles are the ARIA widget roles, which tell assistive technology
+// how to interact with an element.
+var interactiveRoles = map[string]bool{
+	"button":           true,
+	"checkbox":         true,
+	"combobox":         true,
+	"grid":             true,
+	"gridcell":         true,
+	"link":             true,
+	"listbox":          true,
+	"menu":             true,
+	"menubar":          true,
+	"menuitem":         true,
+	"menuitemcheckbox": true,
+	"menuitemradio":    true,
+	"option":           true,
+	"radio":            true,
+	"radiogroup":       true,
+	"scrollbar":        true,
+	"searchbox":        true,
+	"separator":        true,
+	"slider":           true,
+	"spinbutton":       true,
+	"switch":           true,
+	"tab":              true,
+	"tablist":          true,
+	"tabpanel":         true,
+	"textbox":          true,
+	"tree":             true,
+	"treegrid":         true,
+	"treeitem":         true,
+}
+
+// LintTabindexRole ensures that a non-interactive element with tabindex=0 has
+// an interactive role. Otherwise, keyboard users can focus it, but assistive
+// technology does not say what it is for.
+func LintTabindexRole(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode || interactiveElements[node.Data] {
+		return
+	}
+	if tabindex, _ := getAttribute(node.Attr, "tabindex"); strings.TrimSpace(tabindex) != "0" {
+		return
+	}
+	role, _ := getAttribute(node.Attr, "role")
+	for _, r := range strings.Fields(role) {
+		if interactiveRoles[strings.ToLower(r)] {
+			return
+		}
+	}
+	report.Println(pathname, "<"+node.Data+"> has tabindex=0 but no interactive role")
+}
+
+// LintFormControlName ensures that <input>, <select>, and <textarea> in a
+// <form> have a name. The form does not submit controls without one. Buttons
+// and disabled controls are exempt.
+func LintFormControlName(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "input") && !isElement(node, "select") && !isElement(node, "textarea") {
+		return
+	}
+	if !hasParent(node, "form") || hasAttribute(node.Attr, "name", "*") {
+		return
+	}
+	if _, disabled := getAttribute(node.Attr, "disabled"); disabled {
+		return
+	}
+	if isElement(node, "input") {
+		switch inputType(node) {
+		case "button", "image", "reset", "submit":
+			return
+		}
+	}
+	report.Println(pathname, "<"+node.Data+"> in <form> missing name")
+}
+
+// LintMainLandmark ensures that the document has at most 1 visible <main>
+// landmark, and, if Options.RequireMain is set, at least 1.
+func LintMainLandmark(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	mains := findElements(node, "main")
+	visible := 0
+	for _, m := range mains {
+		if _, hidden := getAttribute(m.Attr, "hidden"); !hidden {
+			visible++
+		}
+	}
+	if visible > 1 {
+		report.Println(pathname, "multiple <main> elements:", visible)
+	}
+	if len(mains) == 0 && report.options().RequireMain && !report.fragment {
+		report.Println(pathname, "document has no <main> landmark")
+	}
+}
+
+// disableableElements are the elements that support the disabled attribute.
+var disableableElements = map[string]bool{
+	"button":   true,
+	"fieldset": true,
+	"input":    true,
+	"link":     true,
+	"optgroup": true,
+	"option":   true,
+	"select":   true,
+	"textarea": true,
+}
+
+// LintDisabledMisuse ensures that disabled is only on elements that support
+// it. Elsewhere, such as on <div>, it does nothing. Custom elements, which may
+// be form-associated, are exempt.
+func LintDisabledMisuse(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode || disableableElements[node.Data] || strings.Contains(node.Data, "-") {
+		return
+	}
+	if _, ok := getAttribute(node.Attr, "disabled"); ok {
+		report.Println(pathname, "<"+node.Data+"> does not support disabled")
+	}
+}
+
+// requiredlessInputTypes are the <input> types that do not support required.
+var requiredlessInputTypes = map[string]bool{
+	"button": true,
+	"color":  true,
+	"hidden": true,
+	"image":  true,
+	"range":  true,
+	"reset":  true,
+	"submit": true,
+}
+
+// LintRequiredMisuse ensures that required is only on <input>, <select>, and
+// <textarea>, and not on <input> types that ignore it. Custom elements, which
+// may be form-associated, are exempt.
+func LintRequiredMisuse(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode || strings.Contains(node.Data, "-") {
+		return
+	}
+	if _, ok := getAttribute(node.Attr, "required"); !ok {
+		return
+	}
+	switch {
+	case isElement(node, "select") || isElement(node, "textarea"):
+	case isElement(node, "input"):
+		if t := inputType(node); requiredlessInputTypes[t] {
+			report.Println(pathname, "<input type="+t+"> does not support required")
+		}
+	default:
+		report.Println(pathname, "<"+node.Data+"> does not support required")
+	}
+}
+
+// elementsByID returns the elements in node's subtree that have ids, by id. If
+// several elements have the same id, the first wins, as in the DOM.
+func elementsByID(node *html.Node) map[string]*html.Node {
+	ids := map[string]*html.Node{}
+	for _, n := range findNodes(node, func(n *html.Node) bool { return hasAttribute(n.Attr, "id", "*") }) {
+		id, _ := getAttribute(n.Attr, "id")
+		if _, ok := ids[id]; !ok {
+			ids[id] = n
+		}
+	}
+	return ids
+}
+
+// landmarkRoles maps the ARIA landmark roles to the elements that have them
+// implicitly. <header> and <footer> have them only when they are not inside
+// sectioning content.
+var landmarkRoles = map[string]string{
+	"banner":        "header",
+	"complementary": "aside",
+	"contentinfo":   "footer",
+	"form":          "form",
+	"main":          "main",
+	"navigation":    "nav",
+	"region":        "section",
+	"search":        "search",
+}
+
+// landmarkRole returns the landmark role of node, or "" if it is not a
+// landmark. Unlabeled <section> and <form> are not landmarks, so only their
+// explicit roles count.
+func landmarkRole(node *html.Node) string {
+	if node.Type != html.ElementNode {
+		return ""
+	}
+	if role, ok := getAttribute(node.Attr, "role"); ok {
+		if fields := strings.Fields(strings.ToLower(role)); len(fields) > 0 {
+			if _, ok := landmarkRoles[fields[0]]; ok {
+				return fields[0]
+			}
+			return ""
+		}
+	}
+	switch node.Data {
+	case "header", "footer":
+		for _, tag := range []string{"article", "aside", "main", "nav", "section"} {
+			if hasParent(node, tag) {
+				return ""
+			}
+		}
+		if node.Data == "header" {
+			return "banner"
+		}
+		return "contentinfo"
+	case "section", "form":
+		return ""
+	}
+	for role, tag := range landmarkRoles {
+		if node.Data == tag {
+			return role
+		}
+	}
+	return ""
+}
+
+// LintLandmarkLabels ensures that when there are several landmarks with the
+// same role, such as 2 <nav>s, each has a unique aria-label or
+// aria-labelledby, so that screen reader users can tell them apart. Multiple
+// <main>s are LintMainLandmark's concern.
+func LintLandmarkLabels(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	ids := elementsByID(node)
+	label := func(n *html.Node) string {
+		if value, _ := getAttribute(n.Attr, "aria-label"); strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+		value, _ := getAttribute(n.Attr, "aria-labelledby")
+		var texts []string
+		for _, id := range strings.Fields(value) {
+			if labeller, ok := ids[id]; ok {
+				texts = append(texts, strings.Join(strings.Fields(textContent(labeller)), " "))
+			}
+		}
+		return strings.TrimSpace(strings.Join(texts, " "))
+	}
+
+	landmarks := map[string][]*html.Node{}
+	var roles []string
+	for _, n := range findNodes(node, func(n *html.Node) bool { return landmarkRole(n) != "" }) {
+		role := landmarkRole(n)
+		if _, hidden := getAttribute(n.Attr, "hidden"); hidden || role == "main" {
+			continue
+		}
+		if landmarks[role] == nil {
+			roles = append(roles, role)
+		}
+		landmarks[role] = append(landmarks[role], n)
+	}
+	for _, role := range roles {
+		nodes := landmarks[role]
+		if len(nodes) < 2 {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, n := range nodes {
+			l := strings.ToLower(label(n))
+			if l == "" || seen[l] {
+				report.Println(pathname, len(nodes), role, "landmarks without unique aria-label or aria-labelledby")
+				break
+			}
+			seen[l] = true
+		}
+	}
+}
+
+// AutocompleteTypes maps <input> types to the autocomplete values that
+// LintAutocomplete suggests for them.
+var AutocompleteTypes = map[string]string{
+	"email":    "email",
+	"password": "current-password",
+	"tel":      "tel",
+	"url":      "url",
+}
+
+// AutocompleteNames maps <input> names to the autocomplete values that
+// LintAutocomplete suggests for them, for inputs whose type has no entry in
+// AutocompleteTypes.
+var AutocompleteNames = map[string]string{
+	"address":     "street-address",
+	"city":        "address-level2",
+	"country":     "country-name",
+	"email":       "email",
+	"first-name":  "given-name",
+	"first_name":  "given-name",
+	"firstname":   "given-name",
+	"last-name":   "family-name",
+	"last_name":   "family-name",
+	"lastname":    "family-name",
+	"login":       "username",
+	"name":        "name",
+	"phone":       "tel",
+	"postal-code": "postal-code",
+	"postcode":    "postal-code",
+	"tel":         "tel",
+	"user":        "username",
+	"username":    "username",
+	"zip":         "postal-code",
+}
+
+// LintAutocomplete suggests an autocomplete value for <input>s that lack one,
+// based on their type or name, so that browsers can fill them in.
+func LintAutocomplete(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "input") {
+		return
+	}
+	if _, ok := getAttribute(node.Attr, "autocomplete"); ok {
+		return
+	}
+	suggestion, ok := AutocompleteTypes[inputType(node)]
+	if !ok {
+		name, _ := getAttribute(node.Attr, "name")
+		if suggestion, ok = AutocompleteNames[strings.ToLower(strings.TrimSpace(name))]; !ok {
+			return
+		}
+	}
+	report.Println(pathname, "consider autocomplete="+suggestion)
+}
+
+// readonlyInputTypes are the <input> types that support readonly.
+var readonlyInputTypes = map[string]bool{
+	"date":           true,
+	"datetime-local": true,
+	"email":          true,
+	"month":          true,
+	"number":         true,
+	"password":       true,
+	"search":         true,
+	"tel":            true,
+	"text":           true,
+	"time":           true,
+	"url":            true,
+	"week":           true,
+}
+
+// LintReadonlyMisuse ensures that readonly is only on <textarea> and text-like
+// <input>s. Other controls, such as <select> and checkboxes, ignore it; use
+// disabled instead.
+func LintReadonlyMisuse(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode || strings.Contains(node.Data, "-") {
+		return
+	}
+	if _, ok := getAttribute(node.Attr, "readonly"); !ok {
+		return
+	}
+	switch {
+	case isElement(node, "textarea"):
+	case isElement(node, "input"):
+		if t := inputType(node); !readonlyInputTypes[t] {
+			report.Println(pathname, "<input type="+t+"> ignores readonly")
+		}
+	default:
+		report.Println(pathname, "<"+node.Data+"> ignores readonly")
+	}
+}
+
+// isSubmit returns true if node is a submit button.
+func isSubmit(node *html.Node) bool {
+	if isElement(node, "button") {
+		value, _ := getAttribute(node.Attr, "type")
+		value = strings.ToLower(strings.TrimSpace(value))
+		return value == "" || value == "submit"
+	}
+	return isElement(node, "input") && (inputType(node) == "submit" || inputType(node) == "image")
+}
+
+// LintMultipleSubmit ensures that when a <form> has more than 1 submit button,
+// each has a distinct name and value, so that the server can tell which one
+// was clicked.
+func LintMultipleSubmit(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "form") {
+		return
+	}
+	submits := findNodes(node, isSubmit)
+	if len(submits) < 2 {
+		return
+	}
+	seen := map[string]bool{}
+	for _, n := range submits {
+		name, _ := getAttribute(n.Attr, "name")
+		value, _ := getAttribute(n.Attr, "value")
+		key := name + "=" + value
+		if name == "" || seen[key] {
+			report.Println(pathname, "<form> has", len(submits), "submit buttons without distinct name and value")
+			return
+		}
+		seen[key] = true
+	}
+}
+
+// LintInputButton suggests <button> instead of <input type=button>, submit, or
+// reset. <button> can contain markup, such as icons, and is easier to style.
+func LintInputButton(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "input") {
+		return
+	}
+	switch t := inputType(node); t {
+	case "button", "submit", "reset":
+		report.Println(pathname, "<input type="+t+"> should be <button type="+t+">")
+	}
+}
+
+// LintSubmitValue ensures that <input type=submit> has a value. Otherwise, the
+// browser labels it with a default, such as "Submit", in the browser's
+// language rather than the page's.
+func LintSubmitValue(report *Report, node *html.Node, pathname string) {
+	if isElement(node, "input") && inputType(node) == "submit" && !hasAttribute(node.Attr, "value", "*") {
+		report.Println(pathname, "<input type=submit> missing value")
+	}
+}
+
+// LintNumericConstraints ensures that the min, max, and step of <input
+// type=number> and type=range are numbers, that min is not greater than max,
+// and that step is positive (or any).
+func LintNumericConstraints(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "input") || (inputType(node) != "number" && inputType(node) != "range") {
+		return
+	}
+	values := map[string]float64{}
+	for _, key := range []string{"min", "max", "step"} {
+		value, ok := getAttribute(node.Attr, key)
+		value = strings.TrimSpace(value)
+		if !ok || (key == "step" && strings.EqualFold(value, "any")) {
+			continue
+		}
+		n, e := strconv.ParseFloat(value, 64)
+		if e != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			report.Println(pathname, "<input type="+inputType(node)+">", key, strconv.Quote(value), "is not a number")
+			continue
+		}
+		values[key] = n
+	}
+	min, hasMin := values["min"]
+	max, hasMax := values["max"]
+	if hasMin && hasMax && min > max {
+		report.Println(pathname, "<input type="+inputType(node)+"> min", min, "is greater than max", max)
+	}
+	if step, ok := values["step"]; ok && step <= 0 {
+		report.Println(pathname, "<input type="+inputType(node)+"> step", step, "is not positive")
+	}
+}
+
+// jsOnlyRegexp matches JavaScript regular expression features, such as
+// lookaround and backreferences, that Go's regexp does not support.
+var jsOnlyRegexp = regexp.MustCompile(`\(\?<?[=!]|\\[1-9]|\\k<`)
+
+// LintInputPattern ensures that the pattern attribute of <input> is a valid
+// regular expression. Browsers silently ignore invalid patterns. Go's regexp
+// approximates JavaScript's, so patterns that use JavaScript-only features are
+// not checked.
+func LintInputPattern(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "input") {
+		return
+	}
+	pattern, ok := getAttribute(node.Attr, "pattern")
+	if !ok || jsOnlyRegexp.MatchString(pattern) {
+		return
+	}
+	if _, e := regexp.Compile("^(?:" + pattern + ")$"); e != nil {
+		report.Println(pathname, "<input> pattern", strconv.Quote(pattern), "is not a valid regular expression:", e)
+	}
+}
+
+// suspiciousRune returns a description of r if it is an invisible character
+// that is likely to have been pasted by accident, or to be malicious, such as
+// a bidi override in a "trojan source" attack. Otherwise, it returns "".
+func suspiciousRune(r rune) string {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r' || r == '\f':
+		return ""
+	case unicode.Is(unicode.Cc, r):
+		return "control character"
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069':
+		return "bidi control"
+	case r == '\u200b' || r == '\u2060' || r == '\ufeff':
+		return "zero-width character"
+	}
+	return ""
+}
+
+// LintSuspiciousUnicode ensures that text and attribute values do not contain
+// control characters, bidi overrides, or zero-width characters. <script> and
+// <style> are exempt.
+func LintSuspiciousUnicode(report *Report, node *html.Node, pathname string) {
+	check := func(text, where string) {
+		seen := map[rune]bool{}
+		for _, r := range text {
+			if description := suspiciousRune(r); description != "" && !seen[r] {
+				seen[r] = true
+				report.Println(pathname, fmt.Sprintf("contains %U (%s) in %s", r, description, where))
+			}
+		}
+	}
+	switch node.Type {
+	case html.TextNode:
+		if !hasParent(node, "script") && !hasParent(node, "style") {
+			check(node.Data, "text node")
+		}
+	case html.ElementNode:
+		for _, a := range node.Attr {
+			check(a.Val, "<"+node.Data+"> attribute "+a.Key)
+		}
+	}
+}
+
+// LintLengthConstraints ensures that minlength and maxlength on <input> and
+// <textarea> are non-negative integers, and that minlength is not greater than
+// maxlength, so that the control can validate.
+func LintLengthConstraints(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "input") && !isElement(node, "textarea") {
+		return
+	}
+	values := map[string]int{}
+	for _, key := range []string{"minlength", "maxlength"} {
+		value, ok := getAttribute(node.Attr, key)
+		if !ok {
+			continue
+		}
+		n, e := strconv.Atoi(strings.TrimSpace(value))
+		if e != nil || n < 0 {
+			report.Println(pathname, "<"+node.Data+">", key, strconv.Quote(value), "is not a non-negative integer")
+			continue
+		}
+		values[key] = n
+	}
+	min, hasMin := values["minlength"]
+	max, hasMax := values["maxlength"]
+	if hasMin && hasMax && min > max {
+		report.Println(pathname, "<"+node.Data+"> minlength", min, "is greater than maxlength", max)
+	}
+}
+
+// LintDoctype ensures that the document begins with the HTML5 doctype,
+// <!DOCTYPE html>. Without it, or with a legacy doctype, browsers render the
+// page in quirks mode.
+func LintDoctype(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.DoctypeNode {
+			continue
+		}
+		legacy := false
+		for _, a := range c.Attr {
+			if a.Key == "public" || (a.Key == "system" && a.Val != "about:legacy-compat") {
+				legacy = true
+			}
+		}
+		if c.Data != "html" || legacy {
+			report.Println(pathname, "non-HTML5 DOCTYPE")
+		}
+		return
+	}
+	report.Println(pathname, "missing DOCTYPE")
+}
+
+// formAssociatedElements are the elements that can have a form attribute.
+var formAssociatedElements = map[string]bool{
+	"button":   true,
+	"fieldset": true,
+	"input":    true,
+	"object":   true,
+	"output":   true,
+	"select":   true,
+	"textarea": true,
+}
+
+// LintFormAttribute ensures that the form attribute of a form control refers
+// to the id of a <form>. Otherwise, the control belongs to no form, and is not
+// submitted.
+func LintFormAttribute(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	ids := elementsByID(node)
+	for _, n := range findNodes(node, func(n *html.Node) bool { return n.Type == html.ElementNode && formAssociatedElements[n.Data] }) {
+		id, ok := getAttribute(n.Attr, "form")
+		if !ok {
+			continue
+		}
+		if target, ok := ids[id]; !ok {
+			report.PrintlnAt(report.lines[n], pathname, "<"+n.Data+"> form="+strconv.Quote(id), "refers to no element")
+		} else if !isElement(target, "form") {
+			report.PrintlnAt(report.lines[n], pathname, "<"+n.Data+"> form="+strconv.Quote(id), "refers to <"+target.Data+">, not <form>")
+		}
+	}
+}
+
+// rtlScripts are the Unicode scripts that are written right to left.
+var rtlScripts = []*unicode.RangeTable{
+	unicode.Adlam,
+	unicode.Arabic,
+	unicode.Hebrew,
+	unicode.Mandaic,
+	unicode.Nko,
+	unicode.Samaritan,
+	unicode.Syriac,
+	unicode.Thaana,
+}
+
+// LintHTMLDir ensures that <html> has a dir attribute when most of the
+// document's letters are in right-to-left scripts. Otherwise, the page lays
+// out left to right, and punctuation and mixed text render in the wrong order.
+func LintHTMLDir(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "html") || hasAttribute(node.Attr, "dir", "*") {
+		return
+	}
+	rtl, letters := 0, 0
+	for _, text := range findNodes(node, isProse) {
+		for _, r := range text.Data {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			letters += 1
+			if unicode.IsOneOf(rtlScripts, r) {
+				rtl += 1
+			}
+		}
+	}
+	if rtl > letters/2 {
+		report.Println(pathname, "<html> missing dir=rtl for right-to-left content")
+	}
+}
+
+// LintObjectEmbed ensures that <object> has fallback content, other than
+// <param>s, and that <embed>, which cannot have any, has a title, so that
+// users who cannot see the media know what it is.
+func LintObjectEmbed(report *Report, node *html.Node, pathname string) {
+	if isElement(node, "embed") && !hasAttribute(node.Attr, "title", "*") {
+		report.Println(pathname, "<embed> missing title")
+	}
+	if !isElement(node, "object") {
+		return
+	}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if (c.Type == html.ElementNode && c.Data != "param") || (c.Type == html.TextNode && strings.TrimSpace(c.Data) != "") {
+			return
+		}
+	}
+	report.Println(pathname, "<object> missing fallback content")
+}
+
+// DeprecatedElements maps obsolete elements to what to use instead.
+var DeprecatedElements = map[string]string{
+	"acronym":   "<abbr>",
+	"applet":    "<object>",
+	"basefont":  "CSS",
+	"big":       "CSS",
+	"blink":     "CSS animation",
+	"center":    "CSS",
+	"dir":       "<ul>",
+	"font":      "CSS",
+	"frame":     "<iframe>",
+	"frameset":  "<iframe>",
+	"listing":   "<pre>",
+	"marquee":   "CSS animation",
+	"plaintext": "<pre>",
+	"strike":    "<s> or <del>",
+	"tt":        "<code> or CSS",
+	"xmp":       "<pre>",
+}
+
+// marqueeAttributes are the presentational attributes of <marquee>.
+var marqueeAttributes = []string{"behavior", "direction", "loop", "scrollamount", "scrolldelay"}
+
+// LintDeprecatedElements ensures that the document does not use obsolete
+// elements, such as <center> and <marquee>.
+func LintDeprecatedElements(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode {
+		return
+	}
+	instead, ok := DeprecatedElements[node.Data]
+	if !ok {
+		return
+	}
+	message := "<" + node.Data + "> is obsolete; use " + instead
+	if node.Data == "marquee" {
+		var attributes []string
+		for _, key := range marqueeAttributes {
+			if _, ok := getAttribute(node.Attr, key); ok {
+				attributes = append(attributes, key)
+			}
+		}
+		if len(attributes) > 0 {
+			message += ", and its " + strings.Join(attributes, " and ") + " attributes are doubly obsolete"
+		}
+	}
+	report.Println(pathname, message)
+}
+
+// LintOLAttributes ensures that the start attribute of <ol> is an integer, that
+// its type is 1, a, A, i, or I, and that <ul> does not have the <ol>
+// attributes start, reversed, and type, which do nothing there.
+func LintOLAttributes(report *Report, node *html.Node, pathname string) {
+	if isElement(node, "ul") {
+		for _, key := range []string{"start", "reversed", "type"} {
+			if _, ok := getAttribute(node.Attr, key); ok {
+				report.Println(pathname, "<ul> does not support", key+"; use <ol>")
+			}
+		}
+		return
+	}
+	if !isElement(node, "ol") {
+		return
+	}
+	if start, ok := getAttribute(node.Attr, "start"); ok {
+		if _, e := strconv.Atoi(strings.TrimSpace(start)); e != nil {
+			report.Println(pathname, "<ol> start", strconv.Quote(start), "is not an integer")
+		}
+	}
+	if value, ok := getAttribute(node.Attr, "type"); ok {
+		switch value {
+		case "1", "a", "A", "i", "I":
+		default:
+			report.Println(pathname, "<ol> type", strconv.Quote(value), "is not 1, a, A, i, or I")
+		}
+	}
+}
+
+// LintBrInHeading warns about <br> inside headings, which is often a way to
+// style a heading's layout that belongs in CSS instead.
+func LintBrInHeading(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "br") {
+		return
+	}
+	for p := node.Parent; p != nil; p = p.Parent {
+		if isHeading(p) {
+			report.Println(pathname, "<br> inside <"+p.Data+">; consider CSS for line breaks")
+			return
+		}
+	}
+}
+
+// isScriptType returns true if node is a <script> whose type is kind, such as
+// "module" or "importmap".
+func isScriptType(node *html.Node, kind string) bool {
+	if !isElement(node, "script") {
+		return false
+	}
+	value, _ := getAttribute(node.Attr, "type")
+	return strings.EqualFold(strings.TrimSpace(value), kind)
+}
+
+// LintImportMapOrder ensures that no <script type="importmap"> comes after a
+// <script type="module">. The browser ignores import maps that arrive after
+// module loading has begun.
+func LintImportMapOrder(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	module := false
+	for _, n := range findNodes(node, func(n *html.Node) bool { return isElement(n, "script") }) {
+		if isScriptType(n, "module") {
+			module = true
+		} else if module && isScriptType(n, "importmap") {
+			report.PrintlnAt(report.lines[n], pathname, `<script type="importmap"> comes after <script type="module">`)
+		}
+	}
+}
+
+// LintSingleImportMap ensures that the document has at most 1
+// <script type="importmap">. Browsers do not support more than 1.
+func LintSingleImportMap(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.DocumentNode {
+		return
+	}
+	maps := findNodes(node, func(n *html.Node) bool { return isScriptType(n, "importmap") })
+	for _, n := range maps[min(len(maps), 1):] {
+		report.PrintlnAt(report.lines[n], pathname, `more than 1 <script type="importmap">`)
+	}
+}
+
+// LintImportMapJSON ensures that <script type="importmap"> contains a valid
+// JSON object. Browsers ignore an import map that does not parse, so every
+// module specifier that it maps fails to resolve.
+func LintImportMapJSON(report *Report, node *html.Node, pathname string) {
+	if !isScriptType(node, "importmap") {
+		return
+	}
+	value, e := scriptJSON(node)
+	if e != nil {
+		report.Println(pathname, "invalid import map JSON:", e)
+	} else if _, ok := value.(map[string]interface{}); !ok {
+		report.Println(pathname, "import map is not a JSON object")
+	}
+}
+
+// LintLinkText reports <a> elements whose text, or aria-label if they have
+// one, is in Options.GenericLinkText, such as "click here". Screen reader
+// users often navigate by a list of links, where such text says nothing about
+// where the links go, and search engines use link text to describe the target.
+func LintLinkText(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "a") {
+		return
+	}
+	text, ok := getAttribute(node.Attr, "aria-label")
+	if !ok {
+		text = textContent(node)
+	}
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	text = strings.TrimFunc(text, unicode.IsPunct)
+	for _, generic := range report.options().GenericLinkText {
+		if text == strings.ToLower(generic) {
+			report.Println(pathname, "<a> text", strconv.Quote(text), "does not describe the link")
+			return
+		}
+	}
+}
+
+// LintLazyHighPriority ensures that <img> does not have both loading=lazy and
+// fetchpriority=high. They contradict each other: lazy loading defers an image
+// that the page says is urgent.
+func LintLazyHighPriority(report *Report, node *html.Node, pathname string) {
+	if !isElement(node, "img") {
+		return
+	}
+	loading, _ := getAttribute(node.Attr, "loading")
+	priority, _ := getAttribute(node.Attr, "fetchpriority")
+	if strings.EqualFold(loading, "lazy") && strings.EqualFold(priority, "high") {
+		report.Println(pathname, "<img> has both loading=lazy and fetchpriority=high")
+	}
+}
+
+// removesOutline returns true if the inline CSS style sets outline to none or
+// 0, which hides the focus indicator.
+func removesOutline(style string) bool {
+	for _, declaration := range strings.Split(style, ";") {
+		property, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(property)) {
+		case "outline", "outline-style", "outline-width":
+		default:
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		switch strings.ToLower(value) {
+		case "none", "0", "0px":
+			return true
+		}
+	}
+	return false
+}
+
+// LintOutlineNone ensures that focusable elements do not have an inline style
+// of outline:none or outline:0. That hides the focus indicator, so keyboard
+// users cannot see where they are.
+func LintOutlineNone(report *Report, node *html.Node, pathname string) {
+	if node.Type != html.ElementNode || !interactiveElements[node.Data] && !hasAttribute(node.Attr, "tabindex", "*") {
+		return
+	}
+	if style, ok := getAttribute(node.Attr, "style"); ok && removesOutline(style) {
+		report.Println(pathname, "<"+node.Data+"> style removes the focus outline")
+	}
+}
+
+// A Rule is a named Lint* function. Lint applies Rules that have a Lint
+// function to every node, and LintSource applies Rules that have a LintSource
+// function to the raw source of the document. Rules that have neither, such as
+// max-depth, are reported by Lint itself.
+type Rule struct {
+	Name       string
+	Lint       func(report *Report, node *html.Node, pathname string)
+	LintSource func(report *Report, source []byte, pathname string)
+	// OptIn rules run only when enabled in Options.Enabled.
+	OptIn bool
+	// Document rules check the structure of complete documents, and do not
+	// run on fragments.
+	Document bool
+	Severity Severity
+}
+
+// tokenRule adapts a tokenizer-based Lint* function, such as LintNesting, to
+// Rule.LintSource.
+func tokenRule(lint func(*Report, io.Reader, string)) func(*Report, []byte, string) {
+	return func(report *Report, source []byte, pathname string) {
+		lint(report, bytes.NewReader(source), pathname)
+	}
+}
+
+var rules = []Rule{
+	{Name: "lazy-loading", Lint: LintLazyLoading},
+	{Name: "width-and-height", Lint: LintWidthAndHeight},
+	{Name: "alt-text", Lint: LintAltText},
+	{Name: "a-name", Lint: LintAName},
+	{Name: "iframe-title", Lint: LintIframeTitle},
+	{Name: "insecure-links", Lint: LintInsecureLinks},
+	{Name: "url-syntax", Lint: LintURLSyntax},
+	{Name: "javascript-url", Lint: LintJavaScriptURL, Severity: Warning},
+	{Name: "boolean-attributes", Lint: LintBooleanAttributes},
+	{Name: "input-type", Lint: LintInputType, OptIn: true, Severity: Info},
+	{Name: "icon-button-name", Lint: LintIconButtonName},
+	{Name: "duplicate-tab-stops", Lint: LintDuplicateTabStops, Severity: Warning},
+	{Name: "bold-as-heading", Lint: LintBoldAsHeading, Severity: Info},
+	{Name: "img-figure", Lint: LintImgNestedInFigure},
+	{Name: "aspect-ratio", Lint: LintAspectRatio, OptIn: true},
+	{Name: "time-formatting", Lint: LintTimeFormatting},
+	{Name: "time-datetime", Lint: LintTimeNeedsDatetime},
+	{Name: "time-consistency", Lint: LintTimeConsistency, Severity: Warning},
+	{Name: "object-embed", Lint: LintObjectEmbed, Severity: Warning},
+	{Name: "deprecated-elements", Lint: LintDeprecatedElements},
+	{Name: "ol-attributes", Lint: LintOLAttributes},
+	{Name: "br-in-heading", Lint: LintBrInHeading, OptIn: true, Severity: Warning},
+	{Name: "import-map-order", Lint: LintImportMapOrder},
+	{Name: "single-import-map", Lint: LintSingleImportMap},
+	{Name: "import-map-json", Lint: LintImportMapJSON},
+	{Name: "link-text", Lint: LintLinkText, OptIn: true, Severity: Info},
+	{Name: "lazy-high-priority", Lint: LintLazyHighPriority},
+	{Name: "outline-none", Lint: LintOutlineNone},
+	{Name: "figure-figcaption", Lint: LintFigureHasFigcaption},
+	{Name: "curly-quotes", Lint: LintCurlyQuotes},
+	{Name: "dashes", Lint: LintDashes, OptIn: true},
+	{Name: "ellipsis", Lint: LintEllipsis, OptIn: true},
+	{Name: "double-spaces", Lint: LintDoubleSpaces, OptIn: true},
+	{Name: "text-whitespace", Lint: LintTextWhitespace, OptIn: true},
+	{Name: "double-escape", Lint: LintDoubleEscape},
+	{Name: "code-line-length", Lint: LintCodeLineLength, OptIn: true},
+	{Name: "commented-code", Lint: LintCommentedCode, OptIn: true},
+	{Name: "comment-markers", Lint: LintCommentMarkers, OptIn: true, Severity: Warning},
+	{Name: "conditional-comments", Lint: LintConditionalComments},
+	{Name: "legacy-ie", Lint: LintLegacyIE},
+	{Name: "meta-description", Lint: LintMetaDescription, Document: true},
+	{Name: "open-graph", Lint: LintOpenGraph, OptIn: true, Document: true},
+	{Name: "canonical", Lint: LintCanonical, Document: true},
+	{Name: "favicon", Lint: LintFavicon, OptIn: true, Document: true, Severity: Info},
+	{Name: "autofocus", Lint: LintAutofocus},
+	{Name: "stylesheet-loading", Lint: LintStylesheetLoading, Severity: Info},
+	{Name: "preconnect", Lint: LintPreconnect, OptIn: true, Severity: Info},
+	{Name: "author-meta", Lint: LintAuthorMeta, OptIn: true, Document: true},
+	{Name: "robots-canonical", Lint: LintRobotsCanonicalConflict, Severity: Warning},
+	{Name: "conflicting-meta", Lint: LintConflictingMeta},
+	{Name: "robots-meta", Lint: LintRobotsMeta},
+	{Name: "no-headings", Lint: LintNoHeadings, Document: true, Severity: Warning},
+	{Name: "json-ld", Lint: LintJSONLD},
+	{Name: "json-ld-context", Lint: LintJSONLDContext, OptIn: true, Severity: Warning},
+	{Name: "duplicate-list-items", Lint: LintDuplicateListItems, Severity: Warning},
+	{Name: "empty-list", Lint: LintEmptyList},
+	{Name: "inline-css", Lint: LintInlineCSS, OptIn: true},
+	{Name: "file-upload-form", Lint: LintFileUploadForm},
+	{Name: "aria-level", Lint: LintAriaLevel},
+	{Name: "tabindex-role", Lint: LintTabindexRole, Severity: Warning},
+	{Name: "form-control-name", Lint: LintFormControlName},
+	{Name: "main-landmark", Lint: LintMainLandmark},
+	{Name: "disabled-misuse", Lint: LintDisabledMisuse},
+	{Name: "required-misuse", Lint: LintRequiredMisuse},
+	{Name: "landmark-labels", Lint: LintLandmarkLabels},
+	{Name: "autocomplete", Lint: LintAutocomplete, OptIn: true, Severity: Info},
+	{Name: "readonly-misuse", Lint: LintReadonlyMisuse},
+	{Name: "multiple-submit", Lint: LintMultipleSubmit, Severity: Warning},
+	{Name: "input-button", Lint: LintInputButton, OptIn: true, Severity: Warning},
+	{Name: "submit-value", Lint: LintSubmitValue, Severity: Warning},
+	{Name: "numeric-constraints", Lint: LintNumericConstraints},
+	{Name: "input-pattern", Lint: LintInputPattern},
+	{Name: "suspicious-unicode", Lint: LintSuspiciousUnicode},
+	{Name: "length-constraints", Lint: LintLengthConstraints},
+	{Name: "doctype", Lint: LintDoctype, Document: true},
+	{Name: "form-attribute", Lint: LintFormAttribute},
+	{Name: "html-dir", Lint: LintHTMLDir, Document: true, Severity: Warning},
+	{Name: "max-depth"},
+	{Name: "nesting", LintSource: tokenRule(LintNesting)},
+	{Name: "unclosed-raw-text", LintSource: tokenRule(LintUnclosedRawText)},
+	{Name: "duplicate-attributes", LintSource: tokenRule(LintDuplicateAttributes)},
+	{Name: "table-sections", LintSource: tokenRule(LintTableSections), OptIn: true, Severity: Warning},
+	{Name: "attribute-quoting", LintSource: tokenRule(LintAttributeQuoting), OptIn: true},
+	{Name: "lowercase-names", LintSource: tokenRule(LintLowercaseNames), OptIn: true},
+	{Name: "source-indentation", LintSource: LintSourceIndentation, OptIn: true},
+	{Name: "trailing-whitespace", LintSource: LintTrailingSourceWhitespace, OptIn: true},
+	{Name: "final-newline", LintSource: LintFinalNewline, OptIn: true},
+	{Name: "byte-order-mark", LintSource: LintByteOrderMark, Severity: Warning},
+	{Name: "line-endings", LintSource: LintLineEndings, Severity: Warning},
+}
+
+// severity returns the Severity of rule's findings, which Options.Severity
+// may override.
+func (o *Options) severity(rule Rule) Severity {
+	if name, ok := o.Severity[rule.Name]; ok {
+		if severity, e := ParseSeverity(name); e == nil {
+			return severity
+		}
+	}
+	return rule.Severity
+}
+
+// findRule returns the Rule named name, or nil if there is none.
+func findRule(name string) *Rule {
+	for i := range rules {
+		if rules[i].Name == name {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func (o *Options) isEnabled(rule Rule) bool {
+	if o.Severity[rule.Name] == SeverityOff {
+		return false
+	}
+	if enabled, ok := o.Enabled[rule.Name]; ok {
+		return enabled
+	}
+	return !rule.OptIn
+}
+
+// Lint applies all the enabled Lint* functions to node and each of its
+// descendants.
+func Lint(report *Report, node *html.Node, pathname string) {
+	LintContext(context.Background(), report, node, pathname)
+}
+
+// cancelCheckInterval is how many nodes LintContext visits between checks for
+// cancellation.
+const cancelCheckInterval = 256
+
+// LintContext is like Lint, but stops early and returns ctx.Err() if ctx is
+// done. It does not descend deeper than Options.MaxDepth, and, if the max-depth
+// rule is enabled, reports once if the document is deeper.
+func LintContext(ctx context.Context, report *Report, node *html.Node, pathname string) error {
+	options := report.options()
+	maxDepth := options.MaxDepth
+	visited := 0
+	exceeded := false
+	return walk(node, func(n *html.Node, depth int) error {
+		if visited%cancelCheckInterval == 0 {
+			if e := ctx.Err(); e != nil {
+				return e
+			}
+		}
+		visited += 1
+		lintNode(report, n, pathname)
+
+		if n.FirstChild != nil && maxDepth > 0 && depth >= maxDepth {
+			if rule := findRule("max-depth"); !exceeded && options.isEnabled(*rule) {
+				report.rule = rule
+				report.PrintlnAt(report.lines[n.FirstChild], pathname, "document nesting exceeds max depth", maxDepth)
+				report.rule = nil
+			}
+			exceeded = true
+			return errSkipChildren
+		}
+		return nil
+	})
+}
+
+// Walk calls visit for node and each of its descendants, in document order.
+// It walks iteratively, so pathologically deep documents cannot exhaust the
+// stack.
+func Walk(node *html.Node, visit func(*html.Node)) {
+	walk(node, func(n *html.Node, _ int) error {
+		visit(n)
+		return nil
+	})
+}
+
+// errSkipChildren, returned by a walk visit function, skips the node's
+// children, like filepath.SkipDir.
+var errSkipChildren = errors.New("skip children")
+
+// walk is like Walk, but also passes visit the depth of each node below node.
+// If visit returns errSkipChildren, walk skips the node's children; if it
+// returns any other error, walk stops and returns it.
+func walk(node *html.Node, visit func(n *html.Node, depth int) error) error {
+	depth := 0
+	for n := node; n != nil; {
+		e := visit(n, depth)
+		if e != nil && e != errSkipChildren {
+			return e
+		}
+		if n.FirstChild != nil && e == nil {
+			n, depth = n.FirstChild, depth+1
+			continue
+		}
+		for n != node && n.NextSibling == nil {
+			n, depth = n.Parent, depth-1
+		}
+		if n == node {
+			break
+		}
+		n = n.NextSibling
+	}
+	return nil
+}
+
+// lintNode applies the enabled Lint rules to node.
+func lintNode(report *Report, node *html.Node, pathname string) {
+	options := report.options()
+	report.node = node
+	for i, rule := range rules {
+		if rule.Lint != nil && options.isEnabled(rule) && !(rule.Document && report.fragment) {
+			report.rule = &rules[i]
+			rule.Lint(report, node, pathname)
+		}
+	}
+	report.rule, report.node = nil, nil
+}
+
+// LintReader reads a document from reader, and applies both Lint and
+// LintSource to it. reader need not support seeking. It returns an error if
+// the document cannot be read or parsed.
+func LintReader(report *Report, reader io.Reader, pathname string) error {
+	return LintReaderContext(context.Background(), report, reader, pathname)
+}
+
+// LintReaderContext is like LintReader, but stops early and returns
+// ctx.Err() if ctx is done.
+func LintReaderContext(ctx context.Context, report *Report, reader io.Reader, pathname string) error {
+	return lintReader(ctx, report, reader, pathname, func(source []byte) (*html.Node, error) {
+		return html.Parse(bytes.NewReader(source))
+	})
+}
+
+// LintString lints the document in text with the default Options, including
+// the rules that work on the raw source, and returns the findings. It returns
+// an error if the document cannot be parsed.
+func LintString(text, pathname string) ([]Finding, error) {
+	report := Report{Writer: io.Discard}
+	e := LintReader(&report, strings.NewReader(text), pathname)
+	return report.Findings, e
+}
+
+// LintFragmentReader is like LintReader, but for a fragment of a document,
+// such as a template partial, that would appear inside a contextTag element
+// (such as "body"). It skips Document rules, which check the structure of
+// complete documents.
+func LintFragmentReader(report *Report, reader io.Reader, pathname, contextTag string) error {
+	return LintFragmentReaderContext(context.Background(), report, reader, pathname, contextTag)
+}
+
+// LintFragmentReaderContext is like LintFragmentReader, but stops early and
+// returns ctx.Err() if ctx is done.
+func LintFragmentReaderContext(ctx context.Context, report *Report, reader io.Reader, pathname, contextTag string) error {
+	report.fragment = true
+	defer func() { report.fragment = false }()
+	return lintReader(ctx, report, reader, pathname, func(source []byte) (*html.Node, error) {
+		contextTag = strings.ToLower(contextTag)
+		nodes, e := html.ParseFragment(bytes.NewReader(source), &html.Node{Type: html.ElementNode, Data: contextTag, DataAtom: atom.Lookup([]byte(contextTag))})
+		if e != nil {
+			return nil, e
+		}
+		// Gather the fragment's nodes under a document node, so that
+		// document-wide rules, such as autofocus, still apply.
+		document := &html.Node{Type: html.DocumentNode}
+		for _, n := range nodes {
+			document.AppendChild(n)
+		}
+		return document, nil
+	})
+}
+
+// lintReader reads a document from reader, parses it with parse, and applies
+// both Lint and LintSource to it.
+func lintReader(ctx context.Context, report *Report, reader io.Reader, pathname string, parse func([]byte) (*html.Node, error)) error {
+	if e := ctx.Err(); e != nil {
+		return e
+	}
+	source, e := io.ReadAll(reader)
+	if e != nil {
+		return e
+	}
+	document, e := parse(source)
+	if e != nil {
+		return e
+	}
+	report.lines = nodeLines(source, document)
+	defer func() { report.lines = nil }()
+	if e := LintContext(ctx, report, document, pathname); e != nil {
+		return e
+	}
+	if e := ctx.Err(); e != nil {
+		return e
+	}
+	LintSource(report, source, pathname)
+	return nil
+}
+
+// LintSource applies all the enabled Lint* functions that work on the raw
+// source of a document, rather than on its parsed tree.
+func LintSource(report *Report, source []byte, pathname string) {
+	options := report.options()
+	for i, rule := range rules {
+		if rule.LintSource != nil && options.isEnabled(rule) {
+			report.rule = &rules[i]
+			rule.LintSource(report, source, pathname)
+		}
+	}
+	report.rule = nil
+}
+
+// LintSourceIndentation ensures that lines are indented according to
+// Options.Indentation: with only spaces if it is "spaces", or with only tabs if
+// it is "tabs".
+func LintSourceIndentation(report *Report, source []byte, pathname string) {
+	indentation := report.options().Indentation
+	for i, line := range bytes.Split(source, []byte("\n")) {
+		indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		if indentation == "spaces" && bytes.ContainsRune(indent, '\t') {
+			report.PrintlnAt(i+1, pathname, "indented with tabs")
+		} else if indentation == "tabs" && bytes.ContainsRune(indent, ' ') {
+			report.PrintlnAt(i+1, pathname, "indented with spaces")
+		}
+	}
+}
+
+// LintTrailingSourceWhitespace ensures that source lines do not end with
+// whitespace.
+func LintTrailingSourceWhitespace(report *Report, source []byte, pathname string) {
+	for i, line := range bytes.Split(source, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(bytes.TrimRight(line, " \t")) != len(line) {
+			report.PrintlnAt(i+1, pathname, "has trailing whitespace")
+		}
+	}
+}
+
+// LintFinalNewline ensures that the source ends with a newline.
+func LintFinalNewline(report *Report, source []byte, pathname string) {
+	if len(source) > 0 && source[len(source)-1] != '\n' {
+		report.Println(pathname, "missing final newline")
+	}
+}
+
+// LintByteOrderMark ensures that the source does not begin with a UTF-8 byte
+// order mark, which can cause rendering quirks.
+func LintByteOrderMark(report *Report, source []byte, pathname string) {
+	if bytes.HasPrefix(source, []byte("\xef\xbb\xbf")) {
+		report.Println(pathname, "begins with a byte order mark")
+	}
+}
+
+// LintLineEndings ensures that the source does not mix CRLF and LF line
+// endings, and that it uses the style in Options.LineEndings, if any.
+func LintLineEndings(report *Report, source []byte, pathname string) {
+	crlf := bytes.Count(source, []byte("\r\n"))
+	lf := bytes.Count(source, []byte("\n")) - crlf
+	if crlf > 0 && lf > 0 {
+		report.Println(pathname, "mixes CRLF and LF line endings:", crlf, "CRLF and", lf, "LF")
+		return
+	}
+	switch report.options().LineEndings {
+	case "lf":
+		if crlf > 0 {
+			report.Println(pathname, "uses CRLF line endings; should use LF")
+		}
+	case "crlf":
+		if lf > 0 {
+			report.Println(pathname, "uses LF line endings; should use CRLF")
+		}
+	}
+}
+
+// LintNesting ensures that all tags are properly closed.
+func LintNesting(report *Report, reader io.Reader, pathname string) {
+	z := html.NewTokenizer(reader)
+	var stack []string
+	line := 1
+
+	for {
+		token := z.Next()
+		if token == html.ErrorToken {
+			break
+		}
+		start := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+		tagBytes, _ := z.TagName()
+		tag := string(tagBytes)
+		if token == html.StartTagToken {
+			stack = append(stack, tag)
+		} else if token == html.EndTagToken {
+			if len(stack) == 0 {
+				report.PrintlnAt(start, pathname, "tag stack underflow")
+				continue
+			}
+			last := len(stack) - 1
+			previous := stack[last]
+			if tag != previous {
+				report.PrintlnAt(start, pathname, "Unmatched pair", string(tag), string(previous))
+			}
+			stack = stack[:last]
+		}
+	}
+
+	if len(stack) != 0 {
 		report.Println(pathname, "Unclosed tags", stack)
 	}
 }
+
+// LintUnclosedRawText ensures that raw text elements such as <script> are
+// closed. The tokenizer treats everything after an unclosed <script> as script
+// text, so the rest of the document silently disappears.
+func LintUnclosedRawText(report *Report, reader io.Reader, pathname string) {
+	z := html.NewTokenizer(reader)
+	open := ""
+	openLine := 0
+	line := 1
+
+	for {
+		token := z.Next()
+		if token == html.ErrorToken {
+			break
+		}
+		start := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+		tagBytes, _ := z.TagName()
+		tag := string(tagBytes)
+		if token == html.StartTagToken && rawTextElements[tag] {
+			open, openLine = tag, start
+		} else if token == html.EndTagToken && tag == open {
+			open = ""
+		}
+	}
+
+	if open != "" {
+		report.PrintlnAt(openLine, pathname, "Unclosed raw text element <"+open+"> swallows the rest of the document")
+	}
+}
+
+// LintDuplicateAttributes ensures that no start tag has the same attribute
+// more than once. The parser keeps only the first, so the others silently have
+// no effect.
+func LintDuplicateAttributes(report *Report, reader io.Reader, pathname string) {
+	z := html.NewTokenizer(reader)
+	line := 1
+
+	for {
+		token := z.Next()
+		if token == html.ErrorToken {
+			break
+		}
+		start := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+		if token != html.StartTagToken && token != html.SelfClosingTagToken {
+			continue
+		}
+		tagBytes, hasAttributes := z.TagName()
+		seen := map[string]bool{}
+		for hasAttributes {
+			var key []byte
+			key, _, hasAttributes = z.TagAttr()
+			if seen[string(key)] {
+				report.PrintlnAt(start, pathname, "<"+string(tagBytes)+"> has duplicate attribute", string(key))
+			}
+			seen[string(key)] = true
+		}
+	}
+}
+
+// LintTableSections ensures that every <table> with more than
+// Options.TableSectionRows rows groups them with <thead> or <tbody>. The parser
+// implies a <tbody> if there is none, so this rule reads the tokens instead.
+func LintTableSections(report *Report, reader io.Reader, pathname string) {
+	type table struct {
+		line, rows int
+		sections   bool
+	}
+	threshold := report.options().TableSectionRows
+	check := func(t table) {
+		if t.rows > threshold && !t.sections {
+			report.PrintlnAt(t.line, pathname, "<table> with", t.rows, "rows has no <thead> or <tbody>")
+		}
+	}
+
+	z := html.NewTokenizer(reader)
+	var stack []table
+	line := 1
+	for {
+		token := z.Next()
+		if token == html.ErrorToken {
+			break
+		}
+		start := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+		if token != html.StartTagToken && token != html.EndTagToken {
+			continue
+		}
+		tagBytes, _ := z.TagName()
+		tag := string(tagBytes)
+		if token == html.EndTagToken {
+			if tag == "table" && len(stack) > 0 {
+				check(stack[len(stack)-1])
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		switch {
+		case tag == "table":
+			stack = append(stack, table{line: start})
+		case len(stack) == 0:
+		case tag == "tr":
+			stack[len(stack)-1].rows += 1
+		case tag == "thead" || tag == "tbody":
+			stack[len(stack)-1].sections = true
+		}
+	}
+	for _, t := range stack {
+		check(t)
+	}
+}
+
+// A rawAttribute is an attribute as written in the source.
+type rawAttribute struct {
+	// Name is the attribute's name, in its original case.
+	Name string
+	// Quote is the quotation mark around the value: '"', '\'', 0 if the
+	// value is unquoted, or -1 if there is no value.
+	Quote int
+}
+
+// rawAttributes returns the attributes of the raw start tag, such as the bytes
+// of z.Raw() for a StartTagToken, which the tokenizer otherwise normalizes.
+func rawAttributes(raw []byte) []rawAttribute {
+	var attributes []rawAttribute
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
+	i := 1
+	for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' && raw[i] != '/' {
+		i++
+	}
+	for i < len(raw) {
+		for i < len(raw) && (isSpace(raw[i]) || raw[i] == '/') {
+			i++
+		}
+		if i >= len(raw) || raw[i] == '>' {
+			break
+		}
+		start := i
+		for i++; i < len(raw) && !isSpace(raw[i]) && raw[i] != '=' && raw[i] != '>' && raw[i] != '/'; i++ {
+		}
+		attribute := rawAttribute{Name: string(raw[start:i]), Quote: -1}
+		j := i
+		for j < len(raw) && isSpace(raw[j]) {
+			j++
+		}
+		if j < len(raw) && raw[j] == '=' {
+			for i = j + 1; i < len(raw) && isSpace(raw[i]); i++ {
+			}
+			if i < len(raw) && (raw[i] == '"' || raw[i] == '\'') {
+				attribute.Quote = int(raw[i])
+				end := bytes.IndexByte(raw[i+1:], raw[i])
+				if end < 0 {
+					i = len(raw)
+				} else {
+					i += end + 2
+				}
+			} else {
+				attribute.Quote = 0
+				for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' {
+					i++
+				}
+			}
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes
+}
+
+// LintAttributeQuoting ensures that attribute values are double-quoted in the
+// source. The parser normalizes quoting, so this rule reads the tokens.
+func LintAttributeQuoting(report *Report, reader io.Reader, pathname string) {
+	z := html.NewTokenizer(reader)
+	line := 1
+
+	for {
+		token := z.Next()
+		if token == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		start := line
+		line += bytes.Count(raw, []byte("\n"))
+		if token != html.StartTagToken && token != html.SelfClosingTagToken {
+			continue
+		}
+		tagBytes, _ := z.TagName()
+		for _, a := range rawAttributes(raw) {
+			switch a.Quote {
+			case '\'':
+				report.PrintlnAt(start, pathname, "<"+string(tagBytes)+"> attribute", a.Name, "is single-quoted; should be double-quoted")
+			case 0:
+				report.PrintlnAt(start, pathname, "<"+string(tagBytes)+"> attribute", a.Name, "is unquoted; should be double-quoted")
+			}
+		}
+	}
+}
+
+// rawTagName returns the name of the raw start or end tag, such as the bytes
+// of z.Raw(), in its original case.
+func rawTagName(raw []byte) string {
+	name := bytes.TrimPrefix(bytes.TrimPrefix(raw, []byte("<")), []byte("/"))
+	if i := bytes.IndexAny(name, " \t\n\r\f/>"); i >= 0 {
+		name = name[:i]
+	}
+	return string(name)
+}
+
+// LintLowercaseNames ensures that element and attribute names are lowercase
+// in the source. The parser lowercases them, so this rule reads the tokens.
+// SVG and MathML, which have mixed-case names such as viewBox, are exempt.
+func LintLowercaseNames(report *Report, reader io.Reader, pathname string) {
+	z := html.NewTokenizer(reader)
+	line := 1
+	foreign := 0
+
+	for {
+		token := z.Next()
+		if token == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		start := line
+		line += bytes.Count(raw, []byte("\n"))
+		if token != html.StartTagToken && token != html.SelfClosingTagToken && token != html.EndTagToken {
+			continue
+		}
+		name := rawTagName(raw)
+		lower := strings.ToLower(name)
+		if lower == "svg" || lower == "math" {
+			if token == html.StartTagToken {
+				foreign++
+			} else if token == html.EndTagToken && foreign > 0 {
+				foreign--
+			}
+		}
+		if foreign > 0 && lower != "svg" && lower != "math" {
+			continue
+		}
+		if name != lower {
+			report.PrintlnAt(start, pathname, "<"+name+"> should be lowercase")
+		}
+		if token == html.EndTagToken || foreign > 0 || lower == "svg" || lower == "math" {
+			continue
+		}
+		for _, a := range rawAttributes(raw) {
+			if a.Name != strings.ToLower(a.Name) {
+				report.PrintlnAt(start, pathname, "<"+lower+"> attribute", a.Name, "should be lowercase")
+			}
+		}
+	}
+}
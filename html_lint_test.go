@@ -4,13 +4,70 @@
 package html_lint
 
 import (
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"golang.org/x/net/html"
 )
 
+// documentRules apply to every complete document, so that runTest disables
+// them to keep the test documents small.
+var documentRules = []string{
+	"meta-description",
+	"doctype",
+}
+
 func runTest(t *testing.T, text string, expected []string, expectedErrorCount int) {
+	options := DefaultOptions()
+	options.Enabled = map[string]bool{}
+	for _, name := range documentRules {
+		options.Enabled[name] = false
+	}
+	runTestWithOptions(t, text, options, expected, expectedErrorCount)
+}
+
+// onlyRule returns the default Options with every rule disabled except name.
+func onlyRule(name string) *Options {
+	options := DefaultOptions()
+	options.Enabled = map[string]bool{}
+	for _, r := range rules {
+		options.Enabled[r.Name] = r.Name == name
+	}
+	return options
+}
+
+func runReaderTest(t *testing.T, lint func(*Report, io.Reader, string), text string, expected []string, expectedErrorCount int) {
+	var builder strings.Builder
+	report := Report{Writer: &builder}
+	lint(&report, strings.NewReader(text), "")
+
+	received := builder.String()
+	for _, e := range expected {
+		if !strings.Contains(received, e) {
+			t.Errorf("received %q, expected %q", received, e)
+		}
+	}
+	if report.ErrorCount != expectedErrorCount {
+		t.Errorf("received ErrorCount %d, expected %d", report.ErrorCount, expectedErrorCount)
+	}
+}
+
+func runSourceTest(t *testing.T, source string, options *Options, expected []string, expectedErrorCount int) {
+	runReaderTest(t, func(report *Report, reader io.Reader, pathname string) {
+		report.Options = options
+		source, _ := io.ReadAll(reader)
+		LintSource(report, source, pathname)
+	}, source, expected, expectedErrorCount)
+}
+
+func runTestWithOptions(t *testing.T, text string, options *Options, expected []string, expectedErrorCount int) {
 	reader := strings.NewReader(text)
 	document, e := html.Parse(reader)
 	if e != nil {
@@ -18,7 +75,7 @@ func runTest(t *testing.T, text string, expected []string, expectedErrorCount in
 	}
 
 	var builder strings.Builder
-	report := Report{Writer: &builder, ErrorCount: 0}
+	report := Report{Writer: &builder, ErrorCount: 0, Options: options}
 	Lint(&report, document, "")
 
 	received := builder.String()
@@ -36,7 +93,7 @@ func TestLintLazyLoading(t *testing.T) {
 	document := `
 <figure><img src="goat" alt="goat" width="0" height="0"/>
 <figcaption>goat</figcaption></figure>
-<iframe width="0" height="0"></iframe>
+<iframe width="0" height="0" title="goat"></iframe>
 `
 	expected := []string{
 		"<img>/<iframe> missing loading=lazy",
@@ -44,6 +101,14 @@ func TestLintLazyLoading(t *testing.T) {
 	runTest(t, document, expected, 2)
 }
 
+func TestLintInertContent(t *testing.T) {
+	document := `
+<template><figure><img src="goat" alt="goat"/><figcaption>goat</figcaption></figure></template>
+<noscript><iframe src="goat" title="Don't panic"></iframe></noscript>
+`
+	runTest(t, document, nil, 0)
+}
+
 func TestLintWidthAndHeight(t *testing.T) {
 	document := `
 <figure><img src="goat" alt="goat" height="0" loading="lazy"/>
@@ -58,15 +123,43 @@ func TestLintWidthAndHeight(t *testing.T) {
 	runTest(t, document, expected, 2)
 }
 
+func TestLintDimensions(t *testing.T) {
+	document := `
+<figure><img src="goat" alt="goat" width="abc" height="100px" loading="lazy"/>
+<figcaption>goat</figcaption></figure>
+`
+	expected := []string{
+		"<img> width is not a valid dimension",
+		"<img> height is not a valid dimension",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintMediaWidthAndHeight(t *testing.T) {
+	document := `
+<iframe src="goat" title="goat" height="0" loading="lazy"></iframe>
+<video src="goat" width="100px" height="50"></video>
+<embed src="goat" title="goat" width="100" height="50">
+`
+	expected := []string{
+		"<iframe> missing width",
+		"<video> width is not a valid dimension",
+	}
+	runTest(t, document, expected, 2)
+}
+
 func TestLintAltText(t *testing.T) {
 	document := `
 <figure><img src="goat" width="0" height="0" loading="lazy"/>
 <figcaption>goat</figcaption></figure>
+<input type="image" src="go.png">
+<input type="image" src="go.png" alt="Go">
 `
 	expected := []string{
 		"<img> missing alt",
+		"<input type=image> missing alt",
 	}
-	runTest(t, document, expected, 1)
+	runTest(t, document, expected, 2)
 }
 
 func TestLintAName(t *testing.T) {
@@ -77,6 +170,114 @@ func TestLintAName(t *testing.T) {
 	runTest(t, document, expected, 1)
 }
 
+func TestLintIframeTitle(t *testing.T) {
+	document := `<iframe src="goat" width="0" height="0" loading="lazy"></iframe>`
+	expected := []string{
+		"<iframe> missing title",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintInsecureLinks(t *testing.T) {
+	document := `
+<a href="http://example.com/goats">goats</a>
+<a href="https://example.com/goats">goats</a>
+<a href="http://elsewhere.example/goats">goats</a>
+<form action="http://EXAMPLE.com:8080/submit"></form>
+`
+	expected := []string{
+		"<a> insecure link to host that supports HTTPS http://example.com/goats",
+		"<form> insecure link to host that supports HTTPS",
+	}
+	options := onlyRule("insecure-links")
+	runTestWithOptions(t, document, options, nil, 0)
+	options.HTTPSHosts = []string{"example.com"}
+	runTestWithOptions(t, document, options, expected, 2)
+}
+
+func TestLintURLSyntax(t *testing.T) {
+	document := `
+<a href="htttp://example.com/">typo</a>
+<a href="https://example.com/goat pictures">space</a>
+<a href="javascript:void(0)">script</a>
+<a href="https://example.com/%zz">escape</a>
+<a href="mailto:goat@example.com">mail</a>
+<blockquote cite=" /goats ">quote</blockquote>
+<iframe src="about:blank" title="goat" loading="lazy" width="1" height="1"></iframe>
+<figure><img src="blob:https://example.com/goat" alt="goat" loading="lazy" width="1" height="1"/><figcaption>goat</figcaption></figure>
+`
+	expected := []string{
+		"<a> href has unrecognized scheme htttp",
+		"<a> href contains whitespace",
+		"warning:  <a> href uses javascript: URL",
+		"<a> href is not a valid URL",
+	}
+	runTest(t, document, expected, 4)
+}
+
+func TestLintBooleanAttributes(t *testing.T) {
+	document := `
+<input disabled="false">
+<input required="required" readonly>
+<script type="module" async="true"></script>
+`
+	expected := []string{
+		`<input> boolean attribute disabled="false" is on regardless of its value`,
+		`<script> boolean attribute async="true" is on regardless of its value`,
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintInputType(t *testing.T) {
+	document := `<input name="q"><input type="email" name="e">`
+	expected := []string{
+		"info:",
+		"<input> missing type",
+	}
+	runTestWithOptions(t, document, onlyRule("input-type"), expected, 1)
+}
+
+func TestLintIconButtonName(t *testing.T) {
+	document := `
+<button><svg><path d="M0 0"/></svg></button>
+<a href="/"><i class="icon-home"></i></a>
+<button aria-label="Close"><svg></svg></button>
+<button><svg><title>Close</title></svg></button>
+<button><i class="icon-save"></i> Save</button>
+`
+	expected := []string{
+		"<button> with only an icon missing aria-label, aria-labelledby, or title",
+		"<a> with only an icon missing aria-label, aria-labelledby, or title",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintDuplicateTabStops(t *testing.T) {
+	document := `
+<p><a href="/goats"><b>Goats</b></a>
+<a href="/goats">More about goats</a></p>
+<p><a href="/sheep" tabindex="-1"><b>Sheep</b></a>
+<a href="/sheep">More about sheep</a></p>
+<p><a href="/cows">Cows</a> and <a href="/cows">cows</a></p>
+`
+	expected := []string{
+		"adjacent <a> elements linking to /goats are both in the tab order",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintBoldAsHeading(t *testing.T) {
+	document := `
+<p> <strong>Goats</strong> </p>
+<p><b>Goats</b> are delicious.</p>
+<p><strong>Goats</strong><strong>Sheep</strong></p>
+`
+	expected := []string{
+		"<p> contains only <strong>; should it be a heading?",
+	}
+	runTest(t, document, expected, 1)
+}
+
 func TestLintImgNestedInFigure(t *testing.T) {
 	document := `<img src="goat" width="0" height="0" alt="goat" loading="lazy"/>`
 	expected := []string{
@@ -85,10 +286,49 @@ func TestLintImgNestedInFigure(t *testing.T) {
 	runTest(t, document, expected, 1)
 }
 
+func TestLintAspectRatio(t *testing.T) {
+	directory := t.TempDir()
+	file, e := os.Create(filepath.Join(directory, "goat.png"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e := png.Encode(file, image.NewGray(image.Rect(0, 0, 200, 100))); e != nil {
+		t.Fatal(e)
+	}
+	file.Close()
+
+	document, e := html.Parse(strings.NewReader(`
+<img src="goat.png" width="100" height="50">
+<img src="goat.png" width="100" height="100">
+<img src="https://example.com/goat.png" width="100" height="100">
+<img src="missing.png" width="100" height="100">
+`))
+	if e != nil {
+		t.Fatal(e)
+	}
+	var builder strings.Builder
+	report := Report{Writer: &builder, Options: onlyRule("aspect-ratio")}
+	Lint(&report, document, filepath.Join(directory, "page.html"))
+
+	received := builder.String()
+	for _, e := range []string{
+		"<img> width and height (100x100) do not match the aspect ratio of goat.png (200x100)",
+		"cannot check aspect ratio",
+	} {
+		if !strings.Contains(received, e) {
+			t.Errorf("received %q, expected %q", received, e)
+		}
+	}
+	if report.ErrorCount != 1 {
+		t.Errorf("received ErrorCount %d, expected 1", report.ErrorCount)
+	}
+}
+
 func TestLintTimeFormatting(t *testing.T) {
 	document := `
 <time></time>
 <time>June 99th, 12 BCE</time>
+<time datetime="2024-06-04">June <b>4</b></time>
 `
 	expected := []string{
 		"<time> needs exactly 1 text child",
@@ -97,6 +337,172 @@ func TestLintTimeFormatting(t *testing.T) {
 	runTest(t, document, expected, 2)
 }
 
+func TestLintTimeNeedsDatetime(t *testing.T) {
+	document := `
+<time>1 January 2024</time>
+<time datetime="2024-06-04">last Tuesday</time>
+<time>last Tuesday</time>
+<time datetime="garbage">last Tuesday</time>
+`
+	expected := []string{
+		"<time> child last Tuesday does not have correct format _2 January 2006 and needs a datetime attribute",
+		"<time> datetime garbage is not a valid date or time",
+	}
+	runTest(t, document, expected, 2)
+
+	options := onlyRule("time-datetime")
+	options.TimeLayouts = []string{"2006-01-02", timeFormat}
+	runTestWithOptions(t, `<time>2024-06-04</time>`, options, nil, 0)
+}
+
+func TestLintTimeConsistency(t *testing.T) {
+	document := `
+<time datetime="2023-01-01">2024</time>
+<time datetime="2024-06-04T12:00:00Z">4 June 2024</time>
+<time datetime="2024-06-04">5 June 2024</time>
+<time datetime="2024-06-04">last Tuesday</time>
+`
+	expected := []string{
+		"warning:  <time> text 2024 does not match datetime 2023-01-01",
+		"warning:  <time> text 5 June 2024 does not match datetime 2024-06-04",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintObjectEmbed(t *testing.T) {
+	document := `
+<embed src="goat.svg" width="1" height="1">
+<embed src="goat.svg" width="1" height="1" title="A goat">
+<object data="goat.pdf"><param name="page" value="1"></object>
+<object data="goat.pdf"><a href="goat.pdf">Goats (PDF)</a></object>
+`
+	expected := []string{
+		"warning:  <embed> missing title",
+		"warning:  <object> missing fallback content",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintDeprecatedElements(t *testing.T) {
+	document := `
+<center>Goats</center>
+<marquee>Goats</marquee>
+<marquee behavior="alternate" scrollamount="10">Goats</marquee>
+`
+	expected := []string{
+		"<center> is obsolete; use CSS",
+		"<marquee> is obsolete; use CSS animation\n",
+		"<marquee> is obsolete; use CSS animation, and its behavior and scrollamount attributes are doubly obsolete",
+	}
+	runTest(t, document, expected, 3)
+}
+
+func TestLintOLAttributes(t *testing.T) {
+	document := `
+<ol start="abc"><li>Goats</li></ol>
+<ol reversed start="-3" type="i"><li>Goats</li></ol>
+<ol type="circle"><li>Goats</li></ol>
+<ul start="2"><li>Goats</li></ul>
+`
+	expected := []string{
+		`<ol> start "abc" is not an integer`,
+		`<ol> type "circle" is not 1, a, A, i, or I`,
+		"<ul> does not support start; use <ol>",
+	}
+	runTest(t, document, expected, 3)
+}
+
+func TestLintBrInHeading(t *testing.T) {
+	document := `
+<h1>Goats<br>and more goats</h1>
+<p>Goats<br>and more goats</p>
+`
+	expected := []string{"warning:  <br> inside <h1>; consider CSS for line breaks"}
+	runTestWithOptions(t, document, onlyRule("br-in-heading"), expected, 1)
+}
+
+func TestLintImportMapClean(t *testing.T) {
+	document := `
+<script type="importmap">{"imports": {"goat": "/goat.js"}}</script>
+<script type="module" src="goat.js"></script>
+`
+	runTest(t, document, nil, 0)
+}
+
+func TestLintImportMapOrder(t *testing.T) {
+	document := `
+<script type="module" src="goat.js"></script>
+<script type="importmap">{"imports": {"goat": "/goat.js"}}</script>
+`
+	expected := []string{`<script type="importmap"> comes after <script type="module">`}
+	runTestWithOptions(t, document, onlyRule("import-map-order"), expected, 1)
+}
+
+func TestLintSingleImportMap(t *testing.T) {
+	document := `
+<script type="importmap">{"imports": {"goat": "/goat.js"}}</script>
+<script type="importmap">{"imports": {"sheep": "/sheep.js"}}</script>
+`
+	expected := []string{`more than 1 <script type="importmap">`}
+	runTestWithOptions(t, document, onlyRule("single-import-map"), expected, 1)
+}
+
+func TestLintImportMapJSON(t *testing.T) {
+	document := `
+<script type="importmap">{"imports": {"goat": "/goat.js",}}</script>
+`
+	expected := []string{"invalid import map JSON: invalid character '}'"}
+	runTestWithOptions(t, document, onlyRule("import-map-json"), expected, 1)
+}
+
+func TestSeverityOverrides(t *testing.T) {
+	document := `<p><a name="goat">Goats</a> are "great".</p>`
+	options := onlyRule("a-name")
+	options.Enabled["curly-quotes"] = true
+	options.Severity = map[string]string{"a-name": "warning", "curly-quotes": SeverityOff}
+	expected := []string{"warning:  <a> has name; should use id"}
+	runTestWithOptions(t, document, options, expected, 1)
+}
+
+func TestLintLinkText(t *testing.T) {
+	document := `
+<p>To see goats, <a href="goats.html">click <b>here</b></a>.</p>
+<p><a href="goats.html">Read more…</a></p>
+<p><a href="goats.html" aria-label="Read more about goats">Read more</a></p>
+<p><a href="goats.html">Goats</a></p>
+<p><a href="sheep.html" aria-label="here">Sheep</a></p>
+`
+	expected := []string{
+		`info:  <a> text "click here" does not describe the link`,
+		`info:  <a> text "read more" does not describe the link`,
+		`info:  <a> text "here" does not describe the link`,
+	}
+	runTestWithOptions(t, document, onlyRule("link-text"), expected, 3)
+}
+
+func TestLintLazyHighPriority(t *testing.T) {
+	document := `
+<img src="goat.jpg" alt="Goat" loading="lazy" fetchpriority="high"/>
+<img src="sheep.jpg" alt="Sheep" loading="lazy" fetchpriority="low"/>
+`
+	expected := []string{"<img> has both loading=lazy and fetchpriority=high"}
+	runTestWithOptions(t, document, onlyRule("lazy-high-priority"), expected, 1)
+}
+
+func TestLintOutlineNone(t *testing.T) {
+	document := `
+<button style="color: black; outline:none">Goats</button>
+<div tabindex="0" style="OUTLINE: 0 !important">Goats</div>
+<p style="outline: none">Goats</p>
+<a href="goats.html" style="outline: 2px solid">Goats</a>
+`
+	expected := []string{
+		"<button> style removes the focus outline",
+		"<div> style removes the focus outline",
+	}
+	runTestWithOptions(t, document, onlyRule("outline-none"), expected, 2)
+}
+
 func TestLintFigureHasFigcaption(t *testing.T) {
 	document := `<figure>hello</figure>`
 	expected := []string{
@@ -120,6 +526,937 @@ func TestLintCurlyQuotes(t *testing.T) {
 	runTest(t, document, expected, 3)
 }
 
-func TestLintNesting(t *testing.T) {
-	// TODO
+func TestLintMixedQuotes(t *testing.T) {
+	document := `<p>“hello” and 'world'</p>`
+	expected := []string{
+		"mixes straight and curly quotes in text node",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintDashes(t *testing.T) {
+	document := `<p>foo--bar</p><pre>i--</pre>`
+	expected := []string{
+		"contains -- instead of em dash in text node foo--bar",
+	}
+	runTestWithOptions(t, document, onlyRule("dashes"), expected, 1)
+}
+
+func TestLintEllipsis(t *testing.T) {
+	document := `<p>wait...</p><p>wait…</p>`
+	expected := []string{
+		"contains ... instead of ellipsis in text node wait...",
+	}
+	runTestWithOptions(t, document, onlyRule("ellipsis"), expected, 1)
+}
+
+func TestLintDoubleSpaces(t *testing.T) {
+	document := `<p>hello  world</p>
+<p>
+    indented</p>
+<pre>x  =  1</pre>`
+	expected := []string{
+		"contains multiple spaces in text node hello  world",
+	}
+	runTestWithOptions(t, document, onlyRule("double-spaces"), expected, 1)
+}
+
+func TestLintTextWhitespace(t *testing.T) {
+	document := "<p>Goats\tand sheep</p>\n" +
+		"<p>Goats\u2002and sheep&nbsp;&amp; kids</p>\n" +
+		"<p>See <a href=\"goats\">goats</a> .</p>\n" +
+		"<pre>Goats\tand sheep</pre>\n"
+	expected := []string{
+		`contains tab in text node "Goats\tand sheep"`,
+		`contains U+2002 space in text node`,
+		"space before punctuation after <a>",
+	}
+	runTestWithOptions(t, document, onlyRule("text-whitespace"), expected, 3)
+}
+
+func TestLintDoubleEscape(t *testing.T) {
+	document := `<p>Salt &amp;amp; pepper</p><p>Salt &amp; pepper</p><code>&amp;lt;</code>`
+	expected := []string{
+		"contains double-escaped entity &amp; in text node",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintCodeLineLength(t *testing.T) {
+	document := `<pre>short
+this line is far too long for the reader
+short</pre><p>this line is far too long but is not code</p>`
+	expected := []string{
+		"code line of 40 characters is longer than 20",
+	}
+	options := onlyRule("code-line-length")
+	options.MaxCodeLineLength = 20
+	runTestWithOptions(t, document, options, expected, 1)
+}
+
+func TestLintCommentedCode(t *testing.T) {
+	document := `<!-- <script src="old.js"></script> --><!-- a < b -->`
+	expected := []string{
+		`comment contains commented-out markup <script src="old.js"></script>`,
+	}
+	runTestWithOptions(t, document, onlyRule("commented-code"), expected, 1)
+
+	options := onlyRule("commented-code")
+	options.CommentedCodeMinLength = 40
+	runTestWithOptions(t, document, options, nil, 0)
+}
+
+func TestLintCommentMarkers(t *testing.T) {
+	document := `<!-- TODO: more goats --><!-- FIXME(sheep) --><!-- a todo list --><!-- XXXL -->`
+	expected := []string{
+		"warning:  comment contains TODO: TODO: more goats",
+		"warning:  comment contains FIXME: FIXME(sheep)",
+	}
+	runTestWithOptions(t, document, onlyRule("comment-markers"), expected, 2)
+
+	options := onlyRule("comment-markers")
+	options.CommentMarkers = []string{"todo"}
+	runTestWithOptions(t, document, options, []string{"comment contains todo: a todo list"}, 1)
+
+	document = `<!-- TODOS, then XXX and TODO -->`
+	runTestWithOptions(t, document, onlyRule("comment-markers"), []string{"comment contains XXX:"}, 1)
+}
+
+func TestLintConditionalComments(t *testing.T) {
+	document := `<!--[if IE]><p>Upgrade your browser</p><![endif]--><!-- if only -->`
+	expected := []string{
+		"comment contains obsolete conditional comment syntax",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintLegacyIE(t *testing.T) {
+	document := `<meta http-equiv="x-ua-compatible" content="IE=edge"><![if !IE]><p>Goats</p><![endif]>`
+	expected := []string{
+		"<meta http-equiv=X-UA-Compatible> is obsolete",
+		"comment contains obsolete <![endif]>",
+		"comment contains obsolete conditional comment syntax",
+	}
+	runTest(t, document, expected, 3)
+}
+
+func TestLintMetaDescription(t *testing.T) {
+	only := func(minLength, maxLength int) *Options {
+		options := onlyRule("meta-description")
+		options.MetaDescriptionMinLength = minLength
+		options.MetaDescriptionMaxLength = maxLength
+		return options
+	}
+
+	runTestWithOptions(t, `<p>hello</p>`, only(50, 160), []string{
+		"<head> missing <meta name=description>",
+	}, 1)
+
+	document := `<head><meta name="description" content="  Goats are delicious.  "></head>`
+	runTestWithOptions(t, document, only(50, 160), []string{
+		"<meta name=description> content length 20 not in range 50 to 160",
+	}, 1)
+	runTestWithOptions(t, document, only(10, 20), nil, 0)
+}
+
+func TestLintOpenGraph(t *testing.T) {
+	document := `<head>
+<meta property="og:title" content="Goats">
+<meta property="og:type" content="article">
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="Goats">
+<meta name="twitter:image" content="goat.png">
+</head>`
+	expected := []string{
+		"<head> has og:* metadata but is missing og:image, og:url",
+	}
+	runTestWithOptions(t, document, onlyRule("open-graph"), expected, 1)
+
+	runTestWithOptions(t, `<head><title>Goats</title></head>`, onlyRule("open-graph"), nil, 0)
+}
+
+func TestLintCanonical(t *testing.T) {
+	document := `<head>
+<link rel="canonical" href="https://example.com/goats">
+<link rel="canonical" href="/goats">
+</head>`
+	expected := []string{
+		"<head> has 2 <link rel=canonical>; should have 1",
+		"<link rel=canonical> href /goats is not an absolute URL",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintFavicon(t *testing.T) {
+	options := onlyRule("favicon")
+	runTestWithOptions(t, `<head><title>Goats</title></head>`, options, []string{
+		"info:  <head> missing <link rel=icon>",
+	}, 1)
+
+	document := `<head><link rel="shortcut icon" href="goat.ico"></head>`
+	runTestWithOptions(t, document, options, nil, 0)
+	options.RequireAppleTouchIcon = true
+	runTestWithOptions(t, document, options, []string{
+		"info:  <head> missing <link rel=apple-touch-icon>",
+	}, 1)
+}
+
+func TestLintStylesheetLoading(t *testing.T) {
+	document := `<head>
+<link rel="stylesheet" href="a.css">
+<link rel="stylesheet" href="b.css" media="all">
+<link rel="stylesheet" href="print.css" media="print">
+</head>`
+	expected := []string{
+		"info:",
+		"<head> has 2 render-blocking stylesheets, more than 1; consider a media query or preload for non-critical CSS",
+	}
+	options := onlyRule("stylesheet-loading")
+	runTestWithOptions(t, document, options, nil, 0)
+	options.MaxBlockingStylesheets = 1
+	runTestWithOptions(t, document, options, expected, 1)
+}
+
+func TestLintPreconnect(t *testing.T) {
+	document := `<head>
+<link rel="canonical" href="https://example.com/goats">
+<link rel="preconnect" href="https://fonts.example">
+<link rel="stylesheet" href="https://fonts.example/a.css">
+<link rel="stylesheet" href="https://fonts.example/b.css">
+<script type="module" src="https://cdn.example.com/a.js"></script>
+</head>
+<body>
+<img src="https://cdn.example.com/goat.png">
+<img src="https://example.com/goat.png">
+<img src="https://example.com/sheep.png">
+<img src="https://once.example/goat.png">
+</body>`
+	expected := []string{
+		"origin https://cdn.example.com used 2 times without preconnect",
+	}
+	runTestWithOptions(t, document, onlyRule("preconnect"), expected, 1)
+}
+
+func TestLintAuthorMeta(t *testing.T) {
+	options := onlyRule("author-meta")
+	runTestWithOptions(t, `<head><title>Goats</title></head><p>Goats</p>`, options, []string{
+		"document missing <meta name=author> or rel=author link",
+	}, 1)
+	runTestWithOptions(t, `<head><meta name="author" content="A. Goat"></head>`, options, nil, 0)
+	runTestWithOptions(t, `<p>By <a rel="author" href="/goat">A. Goat</a></p>`, options, nil, 0)
+}
+
+func TestLintRobotsCanonicalConflict(t *testing.T) {
+	document := `<head>
+<meta name="robots" content="noindex, follow">
+<link rel="canonical" href="https://example.com/goats">
+</head>`
+	expected := []string{
+		"<meta name=robots> has noindex but <link rel=canonical> points to https://example.com/goats",
+	}
+	runTest(t, document, expected, 1)
+
+	document = `<head>
+<meta name="robots" content="noindex">
+<meta property="og:url" content="https://example.com/goats/">
+<link rel="canonical" href="https://example.com/goats">
+</head>`
+	runTest(t, document, nil, 0)
+
+	document = `<head>
+<meta name="robots" content="noindex">
+<link rel="canonical" href="https://other.example/">
+</head>`
+	runTest(t, document, []string{"<meta name=robots> has noindex but <link rel=canonical> points to https://other.example/"}, 1)
+}
+
+func TestIsSelfURL(t *testing.T) {
+	for _, c := range []struct {
+		href, pathname, own string
+		expected            bool
+	}{
+		{"", "site/goats.html", "", true},
+		{"#top", "site/goats.html", "", true},
+		{"goats.html", "site/goats.html", "", true},
+		{"./goats.html", "site/goats.html", "", true},
+		{"sheep.html", "site/goats.html", "", false},
+		{"/goats.html", "site/goats.html", "", true},
+		{"/goats", "site/goats.html", "", true},
+		{"/goats/", "site/goats/index.html", "", true},
+		{"/", "site/index.html", "", true},
+		{"/sheep.html", "site/goats.html", "", false},
+		{"https://example.com/goats.html", "site/goats.html", "", false},
+		{"https://other.example/", "blog/index.html", "", false},
+		{"https://other.example/post", "post.html", "", false},
+		{"//other.example/post", "post.html", "", false},
+		{"https://example.com/goats", "<stdin>", "https://example.com/goats/", true},
+		{"https://EXAMPLE.com/goats/", "site/goats.html", "https://example.com/goats", true},
+		{"https://other.example/goats", "site/goats.html", "https://example.com/goats", false},
+		{"https://example.com/sheep", "<stdin>", "https://example.com/goats/", false},
+	} {
+		if received := isSelfURL(c.href, c.pathname, c.own); received != c.expected {
+			t.Errorf("isSelfURL(%q, %q, %q): received %v, expected %v", c.href, c.pathname, c.own, received, c.expected)
+		}
+	}
+}
+
+func TestLintConflictingMeta(t *testing.T) {
+	document := `<html lang="fr"><head>
+<meta charset="utf-8">
+<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1">
+<meta name="viewport" content="width=device-width">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta property="og:locale" content="en_US">
+</head></html>`
+	expected := []string{
+		"<meta> charset iso-8859-1 conflicts with charset utf-8",
+		"document has 2 <meta name=viewport>; should have 1",
+		"<html lang=fr> conflicts with og:locale en_US",
+	}
+	runTest(t, document, expected, 3)
+
+	runTest(t, `<html lang="en-GB"><head><meta charset="UTF-8"><meta charset="utf-8"><meta property="og:locale" content="en_US"></head></html>`, nil, 0)
+
+	runTest(t, `<head><meta charset="utf-8"><meta http-equiv="content-type" content="text/html; charset=iso-8859-1"></head>`, []string{
+		"<meta> charset iso-8859-1 conflicts with charset utf-8",
+	}, 1)
+}
+
+func TestLintRobotsMeta(t *testing.T) {
+	document := `<head><meta name="robots" content="noindex, nofolow, max-snippet:50"></head>`
+	expected := []string{
+		"<meta name=robots> has unknown directive nofolow",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintNoHeadings(t *testing.T) {
+	options := onlyRule("no-headings")
+	options.HeadingWordThreshold = 5
+	runTestWithOptions(t, `<p>Goats are the most delicious of animals.</p>`, options, []string{
+		"<body> has 7 words but no headings",
+	}, 1)
+	runTestWithOptions(t, `<h1>Goats</h1><p>Goats are the most delicious of animals.</p>`, options, nil, 0)
+	runTestWithOptions(t, `<p>Goats are delicious.</p>`, options, nil, 0)
+}
+
+func TestLintJSONLD(t *testing.T) {
+	document := `
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Person", "name": "Goat"}</script>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Person", "name": "Goat",}</script>
+<script type="module">{not JSON}</script>
+`
+	expected := []string{
+		"invalid JSON-LD: invalid character '}' looking for beginning of object key string",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintJSONLDContext(t *testing.T) {
+	document := `
+<script type="application/ld+json">{"@context": "https://schema.org/", "@type": "Person"}</script>
+<script type="application/ld+json">[{"@context": "https://schema.org"}, {"@type": "Person"}]</script>
+<script type="application/ld+json">{"@context": "http://example.com"}</script>
+`
+	expected := []string{
+		"warning:  JSON-LD @context is not https://schema.org",
+	}
+	runTestWithOptions(t, document, onlyRule("json-ld-context"), expected, 2)
+}
+
+func TestLintDuplicateListItems(t *testing.T) {
+	document := `
+<ul>
+<li>Goats</li>
+<li> Goats </li>
+<li>Sheep</li>
+<li>Goats</li>
+</ul>
+`
+	expected := []string{
+		"warning:  adjacent <li> elements have the same text: Goats",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintEmptyList(t *testing.T) {
+	document := `
+<ul>
+</ul>
+<ol><li>Goats</li></ol>
+`
+	expected := []string{
+		"<ul> has no <li>",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintInlineCSS(t *testing.T) {
+	options := onlyRule("inline-css")
+	valid := `<style>
+@charset "utf-8";
+@import url("goat.css");
+/* { unbalanced in a comment */
+@media (min-width: 40em) { p { content: "}"; } }
+@-webkit-keyframes spin { from { opacity: 0 } }
+a[href$='.pdf']::after { content: '\'PDF\'' }
+</style>`
+	runTestWithOptions(t, valid, options, nil, 0)
+
+	for css, message := range map[string]string{
+		"p { color: red":           "<style> has unclosed {",
+		"p { color: red } }":       "<style> has unbalanced }",
+		"p { color: red } h1":      `<style> ends with dangling text "h1"`,
+		"p { color: red } /* goat": "<style> has unterminated comment",
+		"p { content: \"goat }":    "<style> has unterminated string",
+		"@madia print { p { } }":   "<style> has unknown at-rule @madia",
+	} {
+		runTestWithOptions(t, "<style>"+css+"</style>", options, []string{message}, 1)
+	}
+}
+
+func TestLintFileUploadForm(t *testing.T) {
+	document := `
+<form method="POST" enctype="multipart/form-data"><p><input type="file" name="goat"></p></form>
+<form action="/upload"><input type="File" name="goat"></form>
+<form method="post" enctype="application/x-www-form-urlencoded"><input type="file" name="goat"></form>
+<form><input type="text" name="goat"></form>
+`
+	expected := []string{
+		"<form> with <input type=file> missing method=post",
+		"<form> with <input type=file> missing enctype=multipart/form-data",
+	}
+	runTest(t, document, expected, 3)
+}
+
+func TestLintAriaLevel(t *testing.T) {
+	document := `
+<div role="heading" aria-level="2">Goats</div>
+<li role="treeitem" aria-level="1">Goats</li>
+<div aria-level="2">Goats</div>
+<div role="heading" aria-level="zero">Goats</div>
+`
+	expected := []string{
+		"<div> has aria-level without role=heading",
+		`<div> aria-level "zero" is not a positive integer`,
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintTabindexRole(t *testing.T) {
+	document := `
+<div tabindex="0">Goats</div>
+<div tabindex="0" role="button">Goats</div>
+<div tabindex="-1">Goats</div>
+<button tabindex="0">Goats</button>
+`
+	expected := []string{
+		"warning:  <div> has tabindex=0 but no interactive role",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintFormControlName(t *testing.T) {
+	document := `
+<form method="post">
+<input type="text" name="goat">
+<input type="email">
+<select><option>Goat</option></select>
+<textarea disabled></textarea>
+<input type="submit" value="Send">
+</form>
+<input type="text">
+`
+	expected := []string{
+		"<input> in <form> missing name",
+		"<select> in <form> missing name",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintMainLandmark(t *testing.T) {
+	runTest(t, `<main>Goats</main><main hidden>Sheep</main>`, nil, 0)
+	runTest(t, `<main>Goats</main><main>Sheep</main>`, []string{"multiple <main> elements: 2"}, 1)
+	runTest(t, `<p>Goats</p>`, nil, 0)
+
+	options := onlyRule("main-landmark")
+	options.RequireMain = true
+	runTestWithOptions(t, `<p>Goats</p>`, options, []string{"document has no <main> landmark"}, 1)
+}
+
+func TestLintDisabledMisuse(t *testing.T) {
+	document := `
+<div disabled>Goats</div>
+<button disabled>Goats</button>
+<goat-picker disabled></goat-picker>
+`
+	expected := []string{
+		"<div> does not support disabled",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintRequiredMisuse(t *testing.T) {
+	document := `
+<div role="group" required>
+<input type="checkbox" name="goat" required>
+<input type="range" name="goats" required>
+<select name="kid" required><option>Goat</option></select>
+</div>
+`
+	expected := []string{
+		"<div> does not support required",
+		"<input type=range> does not support required",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintLandmarkLabels(t *testing.T) {
+	document := `
+<header>Goats</header>
+<nav aria-label="Main">Goats</nav>
+<nav aria-labelledby="sheep">
+<h2 id="sheep">Sheep</h2>
+</nav>
+<article><header>Kids</header></article>
+<aside>Goats</aside>
+<div role="complementary">Sheep</div>
+`
+	expected := []string{
+		"2 complementary landmarks without unique aria-label or aria-labelledby",
+	}
+	runTest(t, document, expected, 1)
+	runTest(t, `<nav aria-label="Goats"></nav><nav aria-label="goats"></nav>`, []string{"2 navigation landmarks"}, 1)
+}
+
+func TestLintAutocomplete(t *testing.T) {
+	document := `
+<input type="email" name="contact">
+<input type="text" name="Username">
+<input type="password" name="password" autocomplete="new-password">
+<input type="text" name="goat">
+`
+	expected := []string{
+		"info:  consider autocomplete=email",
+		"info:  consider autocomplete=username",
+	}
+	runTestWithOptions(t, document, onlyRule("autocomplete"), expected, 2)
+}
+
+func TestLintReadonlyMisuse(t *testing.T) {
+	document := `
+<select name="goat" readonly><option>Goat</option></select>
+<input type="checkbox" name="kid" readonly>
+<input name="sheep" readonly>
+<textarea name="notes" readonly></textarea>
+`
+	expected := []string{
+		"<select> ignores readonly",
+		"<input type=checkbox> ignores readonly",
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintMultipleSubmit(t *testing.T) {
+	document := `
+<form method="post">
+<button>Save</button>
+<input type="submit" value="Delete">
+<button type="button">Cancel</button>
+</form>
+<form method="post">
+<button name="action" value="save">Save</button>
+<button name="action" value="delete">Delete</button>
+</form>
+`
+	expected := []string{
+		"warning:  <form> has 2 submit buttons without distinct name and value",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintInputButton(t *testing.T) {
+	document := `
+<input type="button" value="Goats">
+<input type="SUBMIT" value="Send">
+<button type="button">Goats</button>
+`
+	expected := []string{
+		"warning:  <input type=button> should be <button type=button>",
+		"warning:  <input type=submit> should be <button type=submit>",
+	}
+	runTestWithOptions(t, document, onlyRule("input-button"), expected, 2)
+}
+
+func TestLintSubmitValue(t *testing.T) {
+	document := `
+<input type="submit">
+<input type="submit" value="Send">
+`
+	expected := []string{
+		"warning:  <input type=submit> missing value",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintNumericConstraints(t *testing.T) {
+	runTest(t, `<input type="number" name="goats" min="0" max="10" step="0.5">`, nil, 0)
+	runTest(t, `<input type="range" name="goats" min="-1" step="any">`, nil, 0)
+	runTest(t, `<input type="number" name="goats" min="10" max="5">`, []string{"<input type=number> min 10 is greater than max 5"}, 1)
+	runTest(t, `<input type="number" name="goats" max="ten">`, []string{`<input type=number> max "ten" is not a number`}, 1)
+	runTest(t, `<input type="range" name="goats" step="0">`, []string{"<input type=range> step 0 is not positive"}, 1)
+	runTest(t, `<input type="number" name="goats" step="-2">`, []string{"<input type=number> step -2 is not positive"}, 1)
+}
+
+func TestLintInputPattern(t *testing.T) {
+	document := `
+<input name="zip" pattern="[0-9]{5}">
+<input name="goat" pattern="(?=.*goat).*">
+<input name="sheep" pattern="[a-z">
+`
+	expected := []string{
+		`<input> pattern "[a-z" is not a valid regular expression: error parsing regexp: missing closing ]`,
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintSuspiciousUnicode(t *testing.T) {
+	document := "<p>Goats\u200band\u200bsheep</p>\n" +
+		"<a href=\"goats\" title=\"\u202egoats\">Goats</a>\n" +
+		"<p>Goats\u0007</p>\n" +
+		"<script type=\"module\">let goats = '\u200b'</script>\n" +
+		"<p>Goats\tand sheep</p>\n"
+	expected := []string{
+		"contains U+200B (zero-width character) in text node",
+		"contains U+202E (bidi control) in <a> attribute title",
+		"contains U+0007 (control character) in text node",
+	}
+	runTest(t, document, expected, 3)
+}
+
+func TestLintLengthConstraints(t *testing.T) {
+	document := `
+<input name="goat" minlength="2" maxlength="10">
+<input name="sheep" minlength="10" maxlength="5">
+<textarea name="kid" maxlength="lots"></textarea>
+`
+	expected := []string{
+		"<input> minlength 10 is greater than maxlength 5",
+		`<textarea> maxlength "lots" is not a non-negative integer`,
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintDoctype(t *testing.T) {
+	options := onlyRule("doctype")
+	runTestWithOptions(t, `<!DOCTYPE html><p>Goats</p>`, options, nil, 0)
+	runTestWithOptions(t, `<!doctype HTML system "about:legacy-compat"><p>Goats</p>`, options, nil, 0)
+	runTestWithOptions(t, `<p>Goats</p>`, options, []string{"missing DOCTYPE"}, 1)
+	runTestWithOptions(t, `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"><p>Goats</p>`, options, []string{"non-HTML5 DOCTYPE"}, 1)
+}
+
+func TestLintFormAttribute(t *testing.T) {
+	document := `
+<form id="goats" method="post"></form>
+<div id="sheep"></div>
+<input name="goat" form="goats">
+<input name="kid" form="kids">
+<button form="sheep">Baa</button>
+`
+	expected := []string{
+		`<input> form="kids" refers to no element`,
+		`<button> form="sheep" refers to <div>, not <form>`,
+	}
+	runTest(t, document, expected, 2)
+}
+
+func TestLintHTMLDir(t *testing.T) {
+	runTest(t, `<html lang="he"><p>עזים וכבשים (goats)</p></html>`, []string{"warning:  <html> missing dir=rtl for right-to-left content"}, 1)
+	runTest(t, `<html lang="he" dir="rtl"><p>עזים וכבשים</p></html>`, nil, 0)
+	runTest(t, `<html lang="en"><p>Goats are עזים in Hebrew.</p></html>`, nil, 0)
+}
+
+func TestLintAutofocus(t *testing.T) {
+	document := `<input type="text" autofocus><textarea autofocus></textarea>`
+	expected := []string{
+		"2 elements have autofocus; should have at most 1: <input>, <textarea>",
+	}
+	runTest(t, document, expected, 1)
+}
+
+func TestLintNesting(t *testing.T) {
+	runReaderTest(t, LintNesting, "<div><p>Goats</p></div>\n", nil, 0)
+	runReaderTest(t, LintNesting, "<div><p>Goats</div></p>\n", []string{":1 Unmatched pair div p", ":1 Unmatched pair p div"}, 2)
+	runReaderTest(t, LintNesting, "<div><p>Goats\n", []string{"Unclosed tags [div p]"}, 1)
+	// Stray end tags at the top level must not index an empty stack.
+	runReaderTest(t, LintNesting, "</p>\n<p>Goats</p>\n</div></span>\n", []string{":1 tag stack underflow", ":3 tag stack underflow"}, 3)
+}
+
+func TestReportCountAtLeast(t *testing.T) {
+	var builder strings.Builder
+	report := Report{Writer: &builder, Options: onlyRule("input-type")}
+	document, _ := html.Parse(strings.NewReader(`<input><img src="goat">`))
+	Lint(&report, document, "")
+	report.Println("", "not from a rule")
+	if n := report.CountAtLeast(Error); n != 1 {
+		t.Errorf("received CountAtLeast(Error) %d, expected 1", n)
+	}
+	if n := report.CountAtLeast(Info); n != 2 {
+		t.Errorf("received CountAtLeast(Info) %d, expected 2", n)
+	}
+}
+
+func TestReportFileError(t *testing.T) {
+	var builder strings.Builder
+	report := Report{Writer: &builder}
+	report.FileError("goat.html", "no such file")
+	report.Println("goat.html", "<img> missing alt")
+	if report.FileErrorCount != 1 {
+		t.Errorf("received FileErrorCount %d, expected 1", report.FileErrorCount)
+	}
+	if report.ErrorCount != 1 {
+		t.Errorf("received ErrorCount %d, expected 1", report.ErrorCount)
+	}
+}
+
+func TestLintUnclosedRawText(t *testing.T) {
+	document := `<p>hello</p><script type="module">let x = 1;<p>goodbye</p>`
+	expected := []string{
+		"Unclosed raw text element <script>",
+	}
+	runReaderTest(t, LintUnclosedRawText, document, expected, 1)
+
+	runReaderTest(t, LintUnclosedRawText, `<style>p {}</style><p>hi</p>`, nil, 0)
+}
+
+func TestLintSourceIndentation(t *testing.T) {
+	source := "<ul>\n\t<li>tab</li>\n  <li>spaces</li>\n</ul>\n"
+	options := onlyRule("source-indentation")
+	runSourceTest(t, source, options, []string{":2 indented with tabs"}, 1)
+	options.Indentation = "tabs"
+	runSourceTest(t, source, options, []string{":3 indented with spaces"}, 1)
+}
+
+func TestLintTrailingSourceWhitespace(t *testing.T) {
+	source := "<p>hello</p>  \r\n<p>world</p>\r\n<p>goat</p>\t\n"
+	expected := []string{
+		":1 has trailing whitespace",
+		":3 has trailing whitespace",
+	}
+	runSourceTest(t, source, onlyRule("trailing-whitespace"), expected, 2)
+}
+
+func TestLintDuplicateAttributes(t *testing.T) {
+	document := `<input type="text" TYPE="email"><p class="a" id="b"></p>`
+	expected := []string{
+		"<input> has duplicate attribute type",
+	}
+	runReaderTest(t, LintDuplicateAttributes, document, expected, 1)
+}
+
+func TestLintTableSections(t *testing.T) {
+	options := onlyRule("table-sections")
+	options.TableSectionRows = 3
+	rows := strings.Repeat("<tr><td>goat</td></tr>\n", 4)
+	runSourceTest(t, "<p>Goats:</p>\n<table>\n"+rows+"</table>\n", options, []string{":2 <table> with 4 rows has no <thead> or <tbody>"}, 1)
+	runSourceTest(t, "<table><thead><tr><th>Goats</th></tr></thead><tbody>\n"+rows+"</tbody></table>", options, nil, 0)
+	runSourceTest(t, "<table>"+strings.Repeat("<tr><td>goat</td></tr>", 3)+"</table>", options, nil, 0)
+}
+
+func TestLintAttributeQuoting(t *testing.T) {
+	source := "<input type=\"checkbox\" checked>\n<a href='goats' title=Goats data-x = \"a'b\">Goats</a>\n<img src=goat.jpg/>\n"
+	expected := []string{
+		":2 <a> attribute href is single-quoted; should be double-quoted",
+		":2 <a> attribute title is unquoted; should be double-quoted",
+		":3 <img> attribute src is unquoted; should be double-quoted",
+	}
+	runSourceTest(t, source, onlyRule("attribute-quoting"), expected, 3)
+}
+
+func TestLintLowercaseNames(t *testing.T) {
+	source := "<DIV CLASS=\"goats\">\n<p>Goats</P>\n<svg viewBox=\"0 0 1 1\"><linearGradient id=\"g\"/></svg>\n</div>\n"
+	expected := []string{
+		":1 <DIV> should be lowercase",
+		":1 <div> attribute CLASS should be lowercase",
+		":2 <P> should be lowercase",
+	}
+	runSourceTest(t, source, onlyRule("lowercase-names"), expected, 3)
+}
+
+func TestLintFinalNewline(t *testing.T) {
+	options := onlyRule("final-newline")
+	runSourceTest(t, "<p>hello</p>", options, []string{"missing final newline"}, 1)
+	runSourceTest(t, "<p>hello</p>\n", options, nil, 0)
+}
+
+func TestLintByteOrderMark(t *testing.T) {
+	options := onlyRule("byte-order-mark")
+	runSourceTest(t, "\ufeff<p>hello</p>\n", options, []string{"begins with a byte order mark"}, 1)
+	runSourceTest(t, "<p>hello\ufeff</p>\n", options, nil, 0)
+}
+
+func TestLintLineEndings(t *testing.T) {
+	options := onlyRule("line-endings")
+	runSourceTest(t, "<p>hello</p>\r\n<p>world</p>\n", options, []string{"mixes CRLF and LF line endings: 1 CRLF and 1 LF"}, 1)
+	runSourceTest(t, "<p>hello</p>\r\n", options, nil, 0)
+	options.LineEndings = "lf"
+	runSourceTest(t, "<p>hello</p>\r\n", options, []string{"uses CRLF line endings; should use LF"}, 1)
+}
+
+// onlyReader hides any other methods, such as Seek, of its Reader.
+type onlyReader struct {
+	io.Reader
+}
+
+func TestLintString(t *testing.T) {
+	document := "<!DOCTYPE html>\n<title>Goats</title>\n<div>\n<p>Goats</div>\n"
+	findings, e := LintString(document, "goat.html")
+	if e != nil {
+		t.Fatal(e)
+	}
+	expected := []Finding{
+		{Pathname: "goat.html", Rule: "meta-description", Message: "<head> missing <meta name=description>"},
+		{Pathname: "goat.html", Line: 4, Rule: "nesting", Message: "Unmatched pair div p"},
+		{Pathname: "goat.html", Rule: "nesting", Message: "Unclosed tags [div]"},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("received %+v, expected %+v", findings, expected)
+	}
+}
+
+func TestLintFragmentReader(t *testing.T) {
+	options := DefaultOptions()
+	options.RequireMain = true
+	fragment := "<figure>\n<img src=\"goat\" width=\"1\" height=\"1\" loading=\"lazy\" autofocus/>\n<figcaption>Goat</figcaption>\n</figure>\n<button autofocus>Baa</button>\n"
+
+	var builder strings.Builder
+	report := Report{Writer: &builder, Options: options}
+	if e := LintFragmentReader(&report, strings.NewReader(fragment), "goat.html", "body"); e != nil {
+		t.Fatal(e)
+	}
+	received := builder.String()
+	for _, e := range []string{"goat.html:2 <img> missing alt", "goat.html 2 elements have autofocus"} {
+		if !strings.Contains(received, e) {
+			t.Errorf("received %q, expected %q", received, e)
+		}
+	}
+	if report.ErrorCount != 2 {
+		t.Errorf("received ErrorCount %d, expected 2: %q", report.ErrorCount, received)
+	}
+
+	// The same markup, as a complete document, is missing a lot.
+	builder.Reset()
+	report = Report{Writer: &builder, Options: options}
+	if e := LintReader(&report, strings.NewReader(fragment), "goat.html"); e != nil {
+		t.Fatal(e)
+	}
+	for _, e := range []string{"missing DOCTYPE", "document has no <main> landmark"} {
+		if !strings.Contains(builder.String(), e) {
+			t.Errorf("received %q, expected %q", builder.String(), e)
+		}
+	}
+}
+
+func TestLintDeepDocument(t *testing.T) {
+	// Build the tree directly, because the parser is slow on deep documents.
+	// All the default rules run, so that any rule that does O(depth) work on
+	// every node, such as calling hasParent, makes this test time out.
+	document := &html.Node{Type: html.DocumentNode}
+	parent := document
+	for i := 0; i < 200000; i++ {
+		div := &html.Node{Type: html.ElementNode, Data: "div"}
+		parent.AppendChild(div)
+		parent = div
+	}
+	parent.AppendChild(&html.Node{Type: html.ElementNode, Data: "img", Attr: []html.Attribute{{Key: "src", Val: "goat"}}})
+
+	var builder strings.Builder
+	report := Report{Writer: &builder, Options: DefaultOptions()}
+	report.Options.MaxDepth = 0
+	Lint(&report, document, "goat.html")
+	if !strings.Contains(builder.String(), "<img> missing alt") {
+		t.Errorf("received %q, expected <img> missing alt", builder.String())
+	}
+}
+
+func TestWalk(t *testing.T) {
+	document, e := html.Parse(strings.NewReader(`<p>Goats <b>and</b> sheep</p><p>Kids</p>`))
+	if e != nil {
+		t.Fatal(e)
+	}
+	var visited []string
+	Walk(findElements(document, "body")[0], func(n *html.Node) {
+		visited = append(visited, n.Data)
+	})
+	expected := []string{"body", "p", "Goats ", "b", "and", " sheep", "p", "Kids"}
+	if strings.Join(visited, "|") != strings.Join(expected, "|") {
+		t.Errorf("received %q, expected %q", visited, expected)
+	}
+}
+
+func TestLintMaxDepth(t *testing.T) {
+	options := onlyRule("alt-text")
+	options.Enabled["max-depth"] = true
+	options.MaxDepth = 5
+	// #document, <html>, <body>, and 2 <div>s are 5 deep.
+	deep := `<div><div><div><img src="goat"></div></div></div><div><div><div>Goats</div></div></div>`
+	runTestWithOptions(t, `<div><div><img src="goat"></div></div>`, options, []string{"<img> missing alt"}, 1)
+	runTestWithOptions(t, deep, options, []string{"document nesting exceeds max depth 5"}, 1)
+
+	options.Severity = map[string]string{"max-depth": "warning"}
+	runTestWithOptions(t, deep, options, []string{"warning:  document nesting exceeds max depth 5"}, 1)
+	// Turning the rule off silences the finding, but not the limit.
+	options.Severity = map[string]string{"max-depth": SeverityOff}
+	runTestWithOptions(t, deep, options, nil, 0)
+}
+
+func TestLintReader(t *testing.T) {
+	var builder strings.Builder
+	report := Report{Writer: &builder, Options: onlyRule("alt-text")}
+	report.Options.Enabled["nesting"] = true
+	reader := onlyReader{strings.NewReader(`<div><figure><img src="goat"/></figure>`)}
+	if e := LintReader(&report, reader, "goat.html"); e != nil {
+		t.Fatal(e)
+	}
+	received := builder.String()
+	for _, e := range []string{"goat.html:1 <img> missing alt", "goat.html Unclosed tags [div]"} {
+		if !strings.Contains(received, e) {
+			t.Errorf("received %q, expected %q", received, e)
+		}
+	}
+	if report.ErrorCount != 2 {
+		t.Errorf("received ErrorCount %d, expected 2", report.ErrorCount)
+	}
+}
+
+// cancelWriter cancels its context when it is first written to.
+type cancelWriter struct {
+	strings.Builder
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.Builder.Write(p)
+}
+
+func TestLintReaderContext(t *testing.T) {
+	const count = 10000
+	document := strings.Repeat(`<figure><img src="goat"/><figcaption>goat</figcaption></figure>`, count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	writer := &cancelWriter{cancel: cancel}
+	report := Report{Writer: writer, Options: onlyRule("alt-text")}
+	e := LintReaderContext(ctx, &report, strings.NewReader(document), "")
+	if e != context.Canceled {
+		t.Errorf("received error %v, expected %v", e, context.Canceled)
+	}
+	if report.ErrorCount == 0 || report.ErrorCount >= count {
+		t.Errorf("received ErrorCount %d, expected between 0 and %d", report.ErrorCount, count)
+	}
+
+	report = Report{Writer: &strings.Builder{}}
+	if e := LintReaderContext(ctx, &report, strings.NewReader(document), ""); e != context.Canceled {
+		t.Errorf("received error %v, expected %v", e, context.Canceled)
+	}
 }
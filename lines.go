@@ -0,0 +1,94 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxSkippedTags is how many start tags nodeLines will skip while looking for
+// an element's start tag, to allow for tags that the parser ignores.
+const maxSkippedTags = 2
+
+// lineToken is a token and the line on which it begins.
+type lineToken struct {
+	Type html.TokenType
+	Data string
+	Line int
+}
+
+// tokenLines tokenizes source, noting the line on which each token begins. For
+// text tokens, the line is that of the first non-whitespace character.
+func tokenLines(source []byte) []lineToken {
+	z := html.NewTokenizer(bytes.NewReader(source))
+	var tokens []lineToken
+	line := 1
+	for {
+		tokenType := z.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		start := line
+		line += bytes.Count(raw, []byte("\n"))
+		if tokenType == html.TextToken {
+			start += bytes.Count(raw[:len(raw)-len(bytes.TrimLeft(raw, " \t\r\n\f"))], []byte("\n"))
+		}
+		tokens = append(tokens, lineToken{Type: tokenType, Data: z.Token().Data, Line: start})
+	}
+	return tokens
+}
+
+// nodeLines returns the lines of source on which the nodes of document begin.
+// The parser does not record positions, so nodeLines matches nodes to tokens in
+// document order. Nodes that the parser implied (such as a missing <tbody>) or
+// moved have no line.
+func nodeLines(source []byte, document *html.Node) map[*html.Node]int {
+	tokens := tokenLines(source)
+	lines := map[*html.Node]int{}
+	cursor := 0
+
+	// match looks for the next token that matches node. If skip is true, it
+	// skips at most maxSkippedTags start tags along the way; otherwise, it
+	// skips none.
+	match := func(node *html.Node, matches func(lineToken) bool, skip bool) {
+		skipped := 0
+		for i := cursor; i < len(tokens); i++ {
+			t := tokens[i]
+			if matches(t) {
+				lines[node] = t.Line
+				cursor = i + 1
+				return
+			}
+			if t.Type == html.StartTagToken || t.Type == html.SelfClosingTagToken {
+				skipped += 1
+				if !skip || skipped > maxSkippedTags {
+					return
+				}
+			}
+		}
+	}
+
+	Walk(document, func(node *html.Node) {
+		switch node.Type {
+		case html.ElementNode:
+			match(node, func(t lineToken) bool {
+				return (t.Type == html.StartTagToken || t.Type == html.SelfClosingTagToken) && t.Data == node.Data
+			}, true)
+		case html.TextNode:
+			data := strings.TrimSpace(node.Data)
+			match(node, func(t lineToken) bool {
+				return t.Type == html.TextToken && strings.HasPrefix(data, strings.TrimSpace(t.Data))
+			}, false)
+		case html.CommentNode:
+			match(node, func(t lineToken) bool { return t.Type == html.CommentToken }, false)
+		case html.DoctypeNode:
+			match(node, func(t lineToken) bool { return t.Type == html.DoctypeToken }, false)
+		}
+	})
+	return lines
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestNodeLines(t *testing.T) {
+	source := "<!doctype html>\n<title>Goats</title>\n<p>\n  Goats are <b>great</b>.\n<!-- baa -->\n<table><tr><td>1</td></tr></table>\n"
+	document, e := html.Parse(strings.NewReader(source))
+	if e != nil {
+		t.Fatal(e)
+	}
+	lines := nodeLines([]byte(source), document)
+
+	for _, c := range []struct {
+		match func(*html.Node) bool
+		line  int
+	}{
+		{func(n *html.Node) bool { return n.Type == html.DoctypeNode }, 1},
+		{func(n *html.Node) bool { return isElement(n, "head") }, 0},
+		{func(n *html.Node) bool { return isElement(n, "title") }, 2},
+		{func(n *html.Node) bool { return isElement(n, "p") }, 3},
+		{func(n *html.Node) bool { return n.Type == html.TextNode && strings.Contains(n.Data, "Goats are") }, 4},
+		{func(n *html.Node) bool { return isElement(n, "b") }, 4},
+		{func(n *html.Node) bool { return n.Type == html.CommentNode }, 5},
+		{func(n *html.Node) bool { return isElement(n, "tbody") }, 0},
+		{func(n *html.Node) bool { return isElement(n, "td") }, 6},
+	} {
+		nodes := findNodes(document, c.match)
+		if len(nodes) != 1 {
+			t.Fatalf("found %d nodes, expected 1", len(nodes))
+		}
+		if lines[nodes[0]] != c.line {
+			t.Errorf("%q: received line %d, expected %d", nodes[0].Data, lines[nodes[0]], c.line)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import "fmt"
+
+// RuleInfo describes a built-in rule, for tools such as editors and
+// documentation generators.
+type RuleInfo struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	// OptIn rules run only when enabled.
+	OptIn bool `json:"opt-in"`
+	// Document rules do not run on fragments.
+	Document bool `json:"document"`
+	// Fixable rules can fix their findings automatically. No rules are
+	// fixable yet.
+	Fixable bool `json:"fixable"`
+}
+
+// ruleDoc is the title and description of a rule.
+type ruleDoc struct {
+	title       string
+	description string
+}
+
+// ruleDocs documents each of the rules, by name.
+var ruleDocs = map[string]ruleDoc{
+	"lazy-loading":         {"Lazy loading", "Ensures that <img> and <iframe> have loading=lazy and that <script> has type=module."},
+	"width-and-height":     {"Media width and height", "Ensures that <img>, <iframe>, <video>, and <embed> have width and height attributes."},
+	"alt-text":             {"Image alt text", "Ensures that <img> and <input type=image> have an alt attribute for accessibility."},
+	"a-name":               {"Deprecated <a name>", "Ensures that <a> does not have the name attribute (which is deprecated in favor of id)."},
+	"iframe-title":         {"Iframe title", "Ensures that <iframe> has a title attribute, which screen readers use to announce it."},
+	"insecure-links":       {"Insecure links", "Ensures that <a href> and <form action> do not use http: URLs to hosts that are listed in the https-hosts option."},
+	"url-syntax":           {"URL syntax", "Ensures that the URL attributes listed in the url-attributes option are valid URLs with recognized schemes."},
+	"javascript-url":       {"JavaScript URLs", "Ensures that href does not use a javascript: URL, which Content Security Policy blocks."},
+	"boolean-attributes":   {"Boolean attribute values", "Ensures that boolean attributes have an empty value or their own name as their value."},
+	"input-type":           {"Explicit input type", "Ensures that <input> has an explicit type."},
+	"icon-button-name":     {"Icon button names", "Ensures that <button> and <a> whose only content is an icon (<svg> or an icon font <i>) have an accessible name from aria-label, aria-labelledby, or title."},
+	"duplicate-tab-stops":  {"Duplicate tab stops", "Ensures that when 2 adjacent <a> elements link to the same URL (typically an image link next to a text link), at most 1 of them is in the tab order."},
+	"bold-as-heading":      {"Bold text as heading", "Ensures that no <p> consists only of a single <strong> or <b>, which is usually a heading in disguise."},
+	"img-figure":           {"Images in figures", "Ensures that <img> is nested inside a <figure> parent."},
+	"aspect-ratio":         {"Image aspect ratio", "Ensures that the width and height attributes of <img> have the same aspect ratio as the image file that src refers to, so that the image is not distorted."},
+	"time-formatting":      {"Time element content", "Ensures that the first child of <time> elements is text."},
+	"time-datetime":        {"Time datetime attribute", "Ensures that <time> elements whose text does not match any of the time-layouts option, such as \"last Tuesday\", have a machine-readable datetime attribute, and that the datetime attribute, if present, parses."},
+	"time-consistency":     {"Time text and datetime agreement", "Ensures that when both the text and the datetime attribute of a <time> parse, they agree, to the precision of the text."},
+	"object-embed":         {"Object and embed fallbacks", "Ensures that <object> has fallback content, other than <param>s, and that <embed>, which cannot have any, has a title, so that users who cannot see the media know what it is."},
+	"deprecated-elements":  {"Deprecated elements", "Ensures that the document does not use obsolete elements, such as <center> and <marquee>."},
+	"ol-attributes":        {"List attributes", "Ensures that the start attribute of <ol> is an integer, that its type is 1, a, A, i, or I, and that <ul> does not have the <ol> attributes start, reversed, and type, which do nothing there."},
+	"br-in-heading":        {"Line breaks in headings", "Warns about <br> inside headings, which is often a way to style a heading's layout that belongs in CSS instead."},
+	"import-map-order":     {"Import map order", "Ensures that no <script type=\"importmap\"> comes after a <script type=\"module\">."},
+	"single-import-map":    {"Single import map", "Ensures that the document has at most 1 <script type=\"importmap\">."},
+	"import-map-json":      {"Import map JSON", "Ensures that <script type=\"importmap\"> contains a valid JSON object."},
+	"link-text":            {"Non-descriptive link text", "Reports <a> elements whose text or aria-label, such as \"click here\", is in the generic-link-text option."},
+	"lazy-high-priority":   {"Lazy high-priority images", "Ensures that <img> does not have both loading=lazy and fetchpriority=high."},
+	"outline-none":         {"Removed focus outline", "Ensures that focusable elements do not have an inline style of outline:none or outline:0."},
+	"figure-figcaption":    {"Figure captions", "Ensures that <figure> has a <figcaption> child."},
+	"curly-quotes":         {"Curly quotes", "Ensures that non-code text nodes, alt attributes, and title attributes use curly quotes."},
+	"dashes":               {"Em dashes", "Ensures that prose text nodes use em dashes (—) rather than double hyphens (--)."},
+	"ellipsis":             {"Ellipses", "Ensures that prose text nodes use the ellipsis character (…) rather than three periods."},
+	"double-spaces":        {"Double spaces", "Ensures that prose text nodes do not separate words with more than 1 space."},
+	"text-whitespace":      {"Unusual whitespace in text", "Ensures that prose text nodes do not contain tabs or unusual space characters, which look like ordinary or non-breaking spaces but behave differently, and that text after an element does not begin with a space before punctuation."},
+	"double-escape":        {"Double-escaped text", "Ensures that prose text nodes are not double-escaped (such as &amp;amp; in the source, which renders as &amp;)."},
+	"code-line-length":     {"Code line length", "Ensures that lines of text in <pre> and <code> are not so long that readers have to scroll horizontally."},
+	"commented-code":       {"Commented-out code", "Ensures that comments longer than the commented-code-min-length option do not contain markup, which is usually commented-out code that should be removed."},
+	"comment-markers":      {"Comment markers", "Ensures that comments do not contain the words in the comment-markers option, such as TODO and FIXME, which are usually leftovers that should not ship."},
+	"conditional-comments": {"Conditional comments", "Ensures that there are no Internet Explorer conditional comments (<!--[if IE]>), which are obsolete."},
+	"legacy-ie":            {"Legacy Internet Explorer markup", "Ensures that there are no <meta http-equiv=\"X-UA-Compatible\"> tags or stray <![endif]> markers, which only Internet Explorer used."},
+	"meta-description":     {"Meta description", "Ensures that <head> has a <meta name=\"description\">, and that its content is neither too short nor too long to be useful in search results."},
+	"open-graph":           {"Open Graph metadata", "Ensures that a document that declares any Open Graph (og:*) or Twitter Card (twitter:*) metadata declares the complete required set, so that link previews work."},
+	"canonical":            {"Canonical link", "Ensures that <head> has at most one <link rel=\"canonical\">, and that its href is an absolute URL."},
+	"favicon":              {"Favicon", "Ensures that <head> declares an icon with <link rel=\"icon\"> (or rel=\"shortcut icon\"), and optionally an apple-touch-icon."},
+	"autofocus":            {"Multiple autofocus", "Ensures that at most 1 element in the document has autofocus."},
+	"stylesheet-loading":   {"Render-blocking stylesheets", "Ensures that <head> does not have too many render-blocking stylesheets: <link rel=\"stylesheet\"> with no media query, or one that matches all media."},
+	"preconnect":           {"Preconnect hints", "Ensures that third-party origins that the document loads resources from more than once have a <link rel=\"preconnect\"> or rel=\"dns-prefetch\"."},
+	"author-meta":          {"Author metadata", "Ensures that the document identifies its author with <meta name=\"author\"> or a rel=\"author\" link."},
+	"robots-canonical":     {"Robots and canonical conflict", "Ensures that a document with <meta name=\"robots\" content=\"noindex\"> does not also have a <link rel=\"canonical\"> to a different URL, which asks search engines to index the canonical URL instead: the 2 signals conflict."},
+	"conflicting-meta":     {"Conflicting metadata", "Ensures that the document does not declare 2 different character encodings, more than 1 viewport, or an og:locale in a different language than <html lang>."},
+	"robots-meta":          {"Robots directives", "Ensures that <meta name=\"robots\"> contains only directives that search engines recognize."},
+	"no-headings":          {"Missing headings", "Ensures that a <body> with more than the heading-word-threshold option's number of words of text has at least 1 heading, which screen reader users navigate by."},
+	"json-ld":              {"JSON-LD syntax", "Ensures that <script type=\"application/ld+json\"> contains valid JSON."},
+	"json-ld-context":      {"JSON-LD context", "Ensures that the top-level objects of JSON-LD structured data have an @context of https://schema.org, which search engines expect."},
+	"duplicate-list-items": {"Duplicate list items", "Ensures that adjacent <li> siblings do not have the same text, which is usually a copy-and-paste mistake."},
+	"empty-list":           {"Empty lists", "Ensures that <ul> and <ol> have at least 1 <li>."},
+	"inline-css":           {"Inline CSS syntax", "Checks <style> for gross syntax errors: unbalanced braces, unterminated comments and strings, text after the last rule, and unknown at-rules."},
+	"file-upload-form":     {"File upload forms", "Ensures that a <form> containing <input type=file> has method=post and enctype=multipart/form-data."},
+	"aria-level":           {"ARIA level", "Ensures that aria-level is a positive integer on an element with a role, such as heading, that supports it."},
+	"tabindex-role":        {"Focusable elements without roles", "Ensures that a non-interactive element with tabindex=0 has an interactive role."},
+	"form-control-name":    {"Form control names", "Ensures that <input>, <select>, and <textarea> in a <form> have a name."},
+	"main-landmark":        {"Main landmark", "Ensures that the document has at most 1 visible <main> landmark, and, with the require-main option, at least 1."},
+	"disabled-misuse":      {"Misplaced disabled", "Ensures that disabled is only on elements that support it."},
+	"required-misuse":      {"Misplaced required", "Ensures that required is only on <input>, <select>, and <textarea>, and not on <input> types that ignore it."},
+	"landmark-labels":      {"Landmark labels", "Ensures that when there are several landmarks with the same role, such as 2 <nav>s, each has a unique aria-label or aria-labelledby, so that screen reader users can tell them apart."},
+	"autocomplete":         {"Autocomplete suggestions", "Suggests an autocomplete value for <input>s that lack one, based on their type or name, so that browsers can fill them in."},
+	"readonly-misuse":      {"Misplaced readonly", "Ensures that readonly is only on <textarea> and text-like <input>s."},
+	"multiple-submit":      {"Multiple submit buttons", "Ensures that when a <form> has more than 1 submit button, each has a distinct name and value, so that the server can tell which one was clicked."},
+	"input-button":         {"Input buttons", "Suggests <button> instead of <input type=button>, submit, or reset."},
+	"submit-value":         {"Submit button value", "Ensures that <input type=submit> has a value."},
+	"numeric-constraints":  {"Numeric input constraints", "Ensures that the min, max, and step of <input type=number> and type=range are numbers, that min is not greater than max, and that step is positive (or any)."},
+	"input-pattern":        {"Input pattern syntax", "Ensures that the pattern attribute of <input> is a valid regular expression."},
+	"suspicious-unicode":   {"Suspicious Unicode", "Ensures that text and attribute values do not contain control characters, bidi overrides, or zero-width characters."},
+	"length-constraints":   {"Input length constraints", "Ensures that minlength and maxlength on <input> and <textarea> are non-negative integers, and that minlength is not greater than maxlength, so that the control can validate."},
+	"doctype":              {"Doctype", "Ensures that the document begins with the HTML5 doctype, <!DOCTYPE html>."},
+	"form-attribute":       {"Form attribute references", "Ensures that the form attribute of a form control refers to the id of a <form>."},
+	"html-dir":             {"Right-to-left direction", "Ensures that <html> has a dir attribute when most of the document's letters are in right-to-left scripts."},
+	"max-depth":            {"Maximum nesting depth", "Reports documents that are nested more deeply than the max-depth option, beyond which they are not linted."},
+	"nesting":              {"Tag nesting", "Ensures that all tags are properly closed."},
+	"unclosed-raw-text":    {"Unclosed raw text elements", "Ensures that raw text elements such as <script> are closed."},
+	"duplicate-attributes": {"Duplicate attributes", "Ensures that no start tag has the same attribute more than once."},
+	"table-sections":       {"Table sections", "Ensures that every <table> with more than the table-section-rows option's number of rows groups them with <thead> or <tbody>."},
+	"attribute-quoting":    {"Attribute quoting", "Ensures that attribute values are double-quoted in the source."},
+	"lowercase-names":      {"Lowercase names", "Ensures that element and attribute names are lowercase in the source."},
+	"source-indentation":   {"Source indentation", "Ensures that lines are indented with only spaces or only tabs, according to the indentation option."},
+	"trailing-whitespace":  {"Trailing whitespace", "Ensures that source lines do not end with whitespace."},
+	"final-newline":        {"Final newline", "Ensures that the source ends with a newline."},
+	"byte-order-mark":      {"Byte order mark", "Ensures that the source does not begin with a UTF-8 byte order mark, which can cause rendering quirks."},
+	"line-endings":         {"Line endings", "Ensures that the source does not mix CRLF and LF line endings, and that it uses the style in the line-endings option, if any."},
+}
+
+// Rules returns a description of each built-in rule, in the order that they
+// run.
+func Rules() []RuleInfo {
+	infos := make([]RuleInfo, 0, len(rules))
+	for _, rule := range rules {
+		doc := ruleDocs[rule.Name]
+		infos = append(infos, RuleInfo{
+			Name:        rule.Name,
+			Title:       doc.title,
+			Description: doc.description,
+			Severity:    rule.Severity,
+			OptIn:       rule.OptIn,
+			Document:    rule.Document,
+		})
+	}
+	return infos
+}
+
+// CheckRuleName returns an error if name is not the name of a rule, suggesting
+// the rule with the most similar name, if any is similar enough.
+func CheckRuleName(name string) error {
+	best, bestDistance := "", len(name)/2+1
+	for _, rule := range rules {
+		if rule.Name == name {
+			return nil
+		}
+		if d := levenshtein(name, rule.Name); d < bestDistance {
+			best, bestDistance = rule.Name, d
+		}
+	}
+	if best == "" {
+		return fmt.Errorf("unknown rule: %s", name)
+	}
+	return fmt.Errorf("unknown rule: %s; did you mean %s?", name, best)
+}
+
+// levenshtein returns the number of single-byte insertions, deletions, and
+// substitutions needed to change a into b.
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
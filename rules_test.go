@@ -0,0 +1,48 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import "testing"
+
+func TestRules(t *testing.T) {
+	infos := Rules()
+	if len(infos) != len(rules) {
+		t.Fatalf("received %d rules, expected %d", len(infos), len(rules))
+	}
+	seen := map[string]bool{}
+	for _, info := range infos {
+		if seen[info.Name] {
+			t.Errorf("duplicate rule %s", info.Name)
+		}
+		seen[info.Name] = true
+		if info.Title == "" || info.Description == "" {
+			t.Errorf("rule %s is not documented in ruleDocs", info.Name)
+		}
+	}
+	for name := range ruleDocs {
+		if !seen[name] {
+			t.Errorf("ruleDocs documents unknown rule %s", name)
+		}
+	}
+	if infos[0].Name != "lazy-loading" || infos[0].Severity != Error || infos[0].OptIn {
+		t.Errorf("received %+v, expected lazy-loading, an error that is not opt-in", infos[0])
+	}
+}
+
+func TestCheckRuleName(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		expected string
+	}{
+		{"img-figure", ""},
+		{"img-fig", "unknown rule: img-fig; did you mean img-figure?"},
+		{"lazyloading", "unknown rule: lazyloading; did you mean lazy-loading?"},
+		{"goats", "unknown rule: goats"},
+	} {
+		e := CheckRuleName(c.name)
+		if c.expected == "" && e != nil || c.expected != "" && (e == nil || e.Error() != c.expected) {
+			t.Errorf("%s: received %v, expected %q", c.name, e, c.expected)
+		}
+	}
+}
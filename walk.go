@@ -0,0 +1,92 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// IgnoreFileName is the name of the file, in gitignore syntax, that lists the
+// pathnames that FindHTMLFiles skips. Patterns are relative to the directory
+// that contains it.
+const IgnoreFileName = ".htmllintignore"
+
+// HTMLExtensions are the filename extensions that FindHTMLFiles looks for.
+var HTMLExtensions = []string{".html", ".htm", ".html.gz", ".htm.gz"}
+
+// IsHTMLFile returns true if pathname has one of the HTMLExtensions.
+func IsHTMLFile(pathname string) bool {
+	for _, extension := range HTMLExtensions {
+		if strings.HasSuffix(strings.ToLower(pathname), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindHTMLFiles returns the pathnames of the HTML files in the tree rooted at
+// root, skipping those that match the patterns in root's IgnoreFileName, if
+// any. It stops early and returns ctx.Err() if ctx is done.
+func FindHTMLFiles(ctx context.Context, root string) ([]string, error) {
+	var pathnames []string
+	e := walkTree(ctx, root, func(pathname string, entry fs.DirEntry) {
+		if !entry.IsDir() && IsHTMLFile(pathname) {
+			pathnames = append(pathnames, pathname)
+		}
+	})
+	return pathnames, e
+}
+
+// FindDirectories returns the pathnames of root and the directories in it,
+// skipping those that FindHTMLFiles skips.
+func FindDirectories(ctx context.Context, root string) ([]string, error) {
+	var pathnames []string
+	e := walkTree(ctx, root, func(pathname string, entry fs.DirEntry) {
+		if entry.IsDir() {
+			pathnames = append(pathnames, pathname)
+		}
+	})
+	return pathnames, e
+}
+
+// walkTree calls visit for root and each file and directory in it that do not
+// match the patterns in root's IgnoreFileName, if any.
+func walkTree(ctx context.Context, root string, visit func(pathname string, entry fs.DirEntry)) error {
+	ignorer, e := ignore.CompileIgnoreFile(filepath.Join(root, IgnoreFileName))
+	if errors.Is(e, fs.ErrNotExist) {
+		ignorer, e = ignore.CompileIgnoreLines(), nil
+	}
+	if e != nil {
+		return e
+	}
+
+	return filepath.WalkDir(root, func(pathname string, entry fs.DirEntry, e error) error {
+		if e != nil {
+			return e
+		}
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		relative, e := filepath.Rel(root, pathname)
+		if e != nil {
+			return e
+		}
+		relative = filepath.ToSlash(relative)
+		if entry.IsDir() {
+			if relative != "." && ignorer.MatchesPath(relative+"/") {
+				return filepath.SkipDir
+			}
+		} else if ignorer.MatchesPath(relative) {
+			return nil
+		}
+		visit(pathname, entry)
+		return nil
+	})
+}
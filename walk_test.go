@@ -0,0 +1,64 @@
+// Copyright 2024 by Chris Palmer, https://noncombatant.org/
+// SPDX-License-Identifier: Apache-2.0
+
+package html_lint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindHTMLFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, pathname := range []string{
+		"index.html",
+		"about/index.htm",
+		"about/notes.txt",
+		"about/archive.html.gz",
+		"about/archive.tar.gz",
+		"vendor/lib/index.html",
+		"node_modules/x/index.html",
+		"assets/app.min.html",
+		"assets/deep/er/app.min.html",
+		"assets/page.html",
+	} {
+		pathname = filepath.Join(root, filepath.FromSlash(pathname))
+		if e := os.MkdirAll(filepath.Dir(pathname), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(pathname, nil, 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	ignore := "vendor/\n**/*.min.html\nnode_modules/\n"
+	if e := os.WriteFile(filepath.Join(root, IgnoreFileName), []byte(ignore), 0o644); e != nil {
+		t.Fatal(e)
+	}
+
+	received, e := FindHTMLFiles(context.Background(), root)
+	if e != nil {
+		t.Fatal(e)
+	}
+	var expected []string
+	for _, pathname := range []string{"about/archive.html.gz", "about/index.htm", "assets/page.html", "index.html"} {
+		expected = append(expected, filepath.Join(root, filepath.FromSlash(pathname)))
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("received %q, expected %q", received, expected)
+	}
+
+	received, e = FindDirectories(context.Background(), root)
+	if e != nil {
+		t.Fatal(e)
+	}
+	expected = []string{root}
+	for _, pathname := range []string{"about", "assets", "assets/deep", "assets/deep/er"} {
+		expected = append(expected, filepath.Join(root, filepath.FromSlash(pathname)))
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("received directories %q, expected %q", received, expected)
+	}
+}